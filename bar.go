@@ -0,0 +1,204 @@
+package menuscreen
+
+import (
+	"strings"
+	"text/template"
+)
+
+// Bar is a templated line drawn above or below the list, see SetHeader,
+// SetFooter, SetStatusLine and SetKeyHints.
+type Bar int
+
+const (
+	HeaderBar Bar = iota
+	StatusBar
+	KeyHintBar
+	FooterBar
+	barCount
+)
+
+// BarPosition is where a bar is drawn, see SetBarPosition.
+type BarPosition int
+
+const (
+	// BarTop draws the bar between the title (or the query line) and the list.
+	BarTop BarPosition = iota
+	// BarBottom draws the bar below the list.
+	BarBottom
+)
+
+// DefaultStatusLine is the template of the status line of a new MenuScreen.
+const DefaultStatusLine = "{{.Matched}}/{{.Total}}{{if .Loading}} " + loadingText + "{{end}}"
+
+// DefaultKeyHints is a key-hint bar for the default key bindings.
+const DefaultKeyHints = "enter: select  /: search  :: custom  esc: quit"
+
+// BarState is the state of the MenuScreen which the templates of the bars
+// are executed over, e.g. "{{.Matched}} of {{.Total}} in {{.Mode}} mode".
+type BarState struct {
+	// Matched is the count of the entries in the list.
+	Matched int
+	// Total is the count of all the entries.
+	Total int
+	// Selected is the count of the marked entries in multi-select mode.
+	Selected int
+	// Mode is one of "normal", "search" and "input".
+	Mode Mode
+	// Query is the query of the search mode.
+	Query string
+	// Input is the customized content of the input mode.
+	Input string
+	// Cursor is the position of the cursor in the list, starts from 1.
+	Cursor int
+	// Matcher is the name of the current matcher.
+	Matcher string
+	// Loading is true while the streaming entries are loading.
+	Loading bool
+	// Message is a message to the user, e.g. why the item can not be chosen,
+	// it's shown in place of the status line until the next key, or below
+	// the bottom bars if there is no status line.
+	Message string
+}
+
+type bar struct {
+	tmpl *template.Template
+	pos  BarPosition
+}
+
+// defaultBars returns the bars of a new MenuScreen, only the status line is shown.
+func defaultBars() [barCount]bar {
+	var bars [barCount]bar
+	bars[StatusBar].tmpl = template.Must(parseBar(StatusBar, DefaultStatusLine))
+	bars[HeaderBar].pos = BarTop
+	for _, b := range []Bar{StatusBar, KeyHintBar, FooterBar} {
+		bars[b].pos = BarBottom
+	}
+	return bars
+}
+
+// SetHeader sets the template of the header, which is drawn at the top by default,
+// see BarState for the fields. An empty tmpl removes the header.
+func (menu *MenuScreen) SetHeader(tmpl string) error {
+	return menu.setBar(HeaderBar, tmpl)
+}
+
+// SetFooter sets the template of the footer, which is drawn at the bottom by default,
+// see BarState for the fields. An empty tmpl removes the footer.
+func (menu *MenuScreen) SetFooter(tmpl string) error {
+	return menu.setBar(FooterBar, tmpl)
+}
+
+// SetStatusLine sets the template of the status line, which is DefaultStatusLine
+// by default, see BarState for the fields. An empty tmpl removes the status line.
+func (menu *MenuScreen) SetStatusLine(tmpl string) error {
+	return menu.setBar(StatusBar, tmpl)
+}
+
+// SetKeyHints sets the key-hint bar, e.g. DefaultKeyHints, it's a template too.
+// An empty hints removes the bar.
+func (menu *MenuScreen) SetKeyHints(hints string) error {
+	return menu.setBar(KeyHintBar, hints)
+}
+
+// SetBarPosition moves the bar to the top or the bottom of the list.
+func (menu *MenuScreen) SetBarPosition(b Bar, pos BarPosition) *MenuScreen {
+	if b >= 0 && b < barCount {
+		menu.bars[b].pos = pos
+		menu.hasFilled = false
+	}
+	return menu
+}
+
+func (menu *MenuScreen) setBar(b Bar, tmpl string) error {
+	if tmpl == "" {
+		menu.bars[b].tmpl = nil
+		menu.hasFilled = false
+		return nil
+	}
+	t, err := parseBar(b, tmpl)
+	if err != nil {
+		return err
+	}
+	menu.bars[b].tmpl = t
+	menu.hasFilled = false
+	return nil
+}
+
+func parseBar(b Bar, tmpl string) (*template.Template, error) {
+	return template.New([...]string{"header", "status", "key-hints", "footer"}[b]).Parse(tmpl)
+}
+
+// barState returns the current state for the templates.
+func (menu *MenuScreen) barState() BarState {
+	state := BarState{
+		Matched:  menu.entryCount(),
+		Total:    len(menu.lines),
+		Selected: len(menu.markedIdxs()),
+		Mode:     menu.mode,
+		Query:    string(menu.query),
+		Input:    string(menu.input),
+		Matcher:  menu.matcher().Name(),
+		Loading:  menu.streaming > 0,
+		Message:  menu.message,
+	}
+	if menu.mode != modeI && menu.cursorY < state.Matched {
+		state.Cursor = menu.cursorY + 1
+	}
+	if menu.heads != nil || menu.tree != nil {
+		// the headers, separators and the ancestors shown for context are not counted
+		state.Matched -= menu.countSections(menu.entries())
+	}
+	if menu.heads != nil {
+		for i := range menu.lines {
+			if menu.kindOf(i) != ItemEntry {
+				state.Total--
+			}
+		}
+	}
+	return state
+}
+
+// barsAt returns the bars at the position in order.
+func (menu *MenuScreen) barsAt(pos BarPosition) []Bar {
+	var bars []Bar
+	for b := Bar(0); b < barCount; b++ {
+		if menu.bars[b].tmpl != nil && menu.bars[b].pos == pos {
+			bars = append(bars, b)
+		}
+	}
+	return bars
+}
+
+// drawBars draws the top bars from the row top, and the bottom bars from the row bottom.
+func (menu *MenuScreen) drawBars(top, bottom int) {
+	state := menu.barState()
+	draw := func(y int, b Bar) {
+		var sb strings.Builder
+		menu.clearLine(y)
+		if b == StatusBar && state.Message != "" {
+			menu.setLineWithStyle(y, state.Message, nil, menu.theme.Error)
+			return
+		}
+		if err := menu.bars[b].tmpl.Execute(&sb, state); err != nil {
+			menu.setLineWithStyle(y, err.Error(), nil, menu.theme.Error)
+			return
+		}
+		// a bar takes only one row
+		text, _, _ := strings.Cut(sb.String(), "\n")
+		menu.setLineWithStyle(y, text, nil, menu.theme.Content)
+	}
+	for i, b := range menu.barsAt(BarTop) {
+		draw(top+i, b)
+	}
+	bottomBars := menu.barsAt(BarBottom)
+	for i, b := range bottomBars {
+		draw(bottom+i, b)
+	}
+	if menu.bars[StatusBar].tmpl == nil && state.Message != "" {
+		// the row may be taken by the last entry, which is redrawn after the next key
+		_, h := menu.screen.Size()
+		y := min(bottom+len(bottomBars), h-1)
+		menu.clearLine(y)
+		menu.setLineWithStyle(y, state.Message, nil, menu.theme.Error)
+	}
+}
@@ -16,18 +16,47 @@
 
 package menuscreen
 
-type screenMode int
+// Mode is the mode of MenuScreen, it's used to bind keys for a specific mode.
+type Mode int
 
 const (
 	// modeN normal mode, read only
-	modeN screenMode = iota
+	modeN Mode = iota
 	// modeS insert mode, writable
 	modeS
 	// modeI insert mode, writable
 	modeI
 )
 
+const (
+	// ModeNormal is the default mode, see modeN.
+	ModeNormal Mode = modeN
+	// ModeSearch is the mode entered by slash, see modeS.
+	ModeSearch Mode = modeS
+	// ModeInput is the mode entered by colon, see modeI.
+	ModeInput Mode = modeI
+)
+
 const (
 	slash = "/"
 	colon = ":"
 )
+
+// markGlyph is drawn in the cursor column for marked lines in multi-select mode.
+const markGlyph = '•'
+
+// loadingText is shown while the preview or the streaming entries are loading.
+const loadingText = "loading..."
+
+// String returns the name of the mode, e.g. "search".
+func (m Mode) String() string {
+	switch m {
+	case modeN:
+		return "normal"
+	case modeS:
+		return "search"
+	case modeI:
+		return "input"
+	}
+	return "unknown"
+}
@@ -17,7 +17,8 @@
 package menuscreen
 
 import (
-	"fmt"
+	"slices"
+	"sort"
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/mattn/go-runewidth"
@@ -26,6 +27,27 @@ import (
 type MenuItem struct {
 	Content string
 	Item    any
+	// Kind makes the item a section header or a separator, see ItemKind.
+	Kind ItemKind
+	// Disabled items are drawn dimmed, and can not be chosen,
+	// the DisabledReason is shown in the status line on enter.
+	Disabled       bool
+	DisabledReason string
+	// Columns are the cells of the item in a table, see SetColumns.
+	// The Content is the columns joined by spaces if it's empty.
+	Columns []string
+	// Children make the item a node of a tree, they are drawn under it
+	// with the indentation guides. 'l' / '→' expands the node and 'h' / '←'
+	// collapses it in the normal mode. Collapsed hides the children at first.
+	Children  []*MenuItem
+	Collapsed bool
+	// Style is the base style of the item, and the Spans style parts of the
+	// Content over it. They are drawn over the style of the line in the theme,
+	// the colors they set replace the ones of the theme and the attributes
+	// are combined. The chosen line style of the theme is drawn over them on
+	// the chosen line, and the highlight of the matched chars is drawn at last.
+	Style tcell.Style
+	Spans []Span
 }
 
 func (menu *MenuScreen) SetTitle(title string) *MenuScreen {
@@ -35,18 +57,43 @@ func (menu *MenuScreen) SetTitle(title string) *MenuScreen {
 
 func (menu *MenuScreen) ClearLines() *MenuScreen {
 	menu.lines = nil
+	menu.items = nil
 	menu.matchedLns = nil
+	menu.marked = make(map[int]struct{})
+	menu.collapsed = make(map[int]bool)
+	menu.tree = nil
+	menu.resetPreviewCache()
+	menu.resetTargets()
+	menu.resetNormalLines()
 	return menu
 }
 
 func (menu *MenuScreen) SetLines(lns ...string) *MenuScreen {
 	menu.cursorY = 0
 	menu.lines = lns
+	menu.items = nil
+	menu.marked = make(map[int]struct{})
+	menu.collapsed = make(map[int]bool)
+	menu.tree = nil
+	menu.resetPreviewCache()
+	menu.resetTargets()
+	menu.resetNormalLines()
+	return menu
+}
+
+// SetMultiSelect enables the multi-select mode, 'tab' and 'shift-tab' will
+// toggle the mark of the current line. Use ChosenLines or ChosenItems to get
+// all the marked lines.
+func (menu *MenuScreen) SetMultiSelect(enable bool) *MenuScreen {
+	menu.multiSelect = enable
 	return menu
 }
 
 func (menu *MenuScreen) SetLine(n int, content string) *MenuScreen {
 	menu.cursorY = 0
+	menu.resetPreviewCache()
+	menu.resetTargets()
+	menu.resetNormalLines()
 	if n < 0 {
 		panic("line number should greater than 0")
 	} else if n < len(menu.lines) {
@@ -69,91 +116,136 @@ func (menu *MenuScreen) AppendLines(content ...string) *MenuScreen {
 	return menu
 }
 
+// ChosenLine returns the chosen line with its original index,
+// the index is -1 for the customized content of the input mode.
 func (menu *MenuScreen) ChosenLine() (idx int, ln string, ok bool) {
 	if !menu.confirmed {
 		return -1, "", false
 	}
-	ok = true
-	idx = menu.cursorY
 	if menu.mode == modeI {
-		idx = -1
-		ln = string(menu.input)
-	}
-	if menu.mode == modeN && len(menu.lines) > 0 {
-		ln = menu.lines[idx]
+		return -1, string(menu.input), true
 	}
-	if menu.mode == modeS && len(menu.matchedLns) > 0 {
-		ln = menu.matchedLns[idx].content
-		idx = menu.matchedLns[idx].idx
+	if idx, ok = menu.cursorIdx(); !ok {
+		return -1, "", false
 	}
-	return
+	return idx, menu.lines[idx], true
 }
 
 // AppendItems append items to the end of the menu.
 // WARN: do not call AppendItems and AppendLines at the same time.
 func (menu *MenuScreen) AppendItems(items ...*MenuItem) *MenuScreen {
-	menu.items = append(menu.items, items...)
 	for _, item := range items {
-		menu.lines = append(menu.lines, item.Content)
+		menu.appendTree(item, -1, 0)
 	}
 	return menu
 }
 
+// ChosenItem returns the chosen item with its original index, it works for
+// the line menu too, see ChosenLine.
 func (menu *MenuScreen) ChosenItem() (idx int, item *MenuItem, ok bool) {
 	if !menu.confirmed {
 		return -1, nil, false
 	}
-	ok = true
-	idx = menu.cursorY
 	if menu.mode == modeI {
-		idx = -1
-		item = &MenuItem{
-			Content: string(menu.input),
+		return -1, &MenuItem{Content: string(menu.input)}, true
+	}
+	if idx, ok = menu.cursorIdx(); !ok {
+		return -1, nil, false
+	}
+	return idx, menu.itemAt(idx), true
+}
+
+// ChosenLines returns all the marked lines with their original indexes in order.
+// If no line was marked, the chosen line will be returned just like ChosenLine.
+func (menu *MenuScreen) ChosenLines() (idxs []int, lns []string, ok bool) {
+	if !menu.confirmed {
+		return nil, nil, false
+	}
+	if menu.mode == modeI || len(menu.marked) == 0 {
+		idx, ln, ok := menu.ChosenLine()
+		if !ok {
+			return nil, nil, false
+		}
+		return []int{idx}, []string{ln}, true
+	}
+	idxs = menu.markedIdxs()
+	lns = make([]string, 0, len(idxs))
+	for _, idx := range idxs {
+		lns = append(lns, menu.lines[idx])
+	}
+	return idxs, lns, true
+}
+
+// ChosenItems returns all the marked items with their original indexes in order.
+// If no item was marked, the chosen item will be returned just like ChosenItem.
+func (menu *MenuScreen) ChosenItems() (idxs []int, items []*MenuItem, ok bool) {
+	if !menu.confirmed {
+		return nil, nil, false
+	}
+	if menu.mode == modeI || len(menu.marked) == 0 {
+		idx, item, ok := menu.ChosenItem()
+		if !ok {
+			return nil, nil, false
 		}
+		return []int{idx}, []*MenuItem{item}, true
+	}
+	idxs = menu.markedIdxs()
+	items = make([]*MenuItem, 0, len(idxs))
+	for _, idx := range idxs {
+		items = append(items, menu.itemAt(idx))
 	}
-	if menu.mode == modeN && len(menu.lines) > 0 {
-		item = menu.items[idx]
+	return idxs, items, true
+}
+
+// itemAt returns the item of the n-th line, a new item is created for the line menu.
+func (menu *MenuScreen) itemAt(n int) *MenuItem {
+	if n < len(menu.items) {
+		return menu.items[n]
 	}
-	if menu.mode == modeS && len(menu.matchedLns) > 0 {
-		m := menu.matchedLns[idx]
-		idx = m.idx
-		item = &MenuItem{
-			Content: m.content,
-			Item:    m.item,
+	return &MenuItem{Content: menu.lines[n]}
+}
+
+func (menu *MenuScreen) markedIdxs() []int {
+	idxs := make([]int, 0, len(menu.marked))
+	for idx := range menu.marked {
+		if idx < len(menu.lines) {
+			idxs = append(idxs, idx)
 		}
 	}
-	return
+	sort.Ints(idxs)
+	return idxs
 }
 
 func (menu *MenuScreen) refreshScreen() {
 
 	defer menu.screen.Show()
 
+	menu.fixCursor()
+	menu.scrollToCursor()
+
 	switch menu.mode {
 	case modeN:
 		// PERF: only fill screen when the screen is not filled.
 		if !menu.hasFilled {
 			menu.screen.Clear()
-			menu.fillScreen(strSliceToMatchedLines(menu.lines))
+			menu.fillScreen(menu.entries())
 			menu.hasFilled = true
 		}
 		menu.screen.HideCursor()
 		menu.resetChosenLine()
+		// the bars may show the cursor position
+		menu.drawBars(menu.barRows())
+		menu.drawPreview()
 	case modeS:
 		menu.screen.Clear()
-		lns := make([]*matchedLine, 0, 16)
-		lns = append(lns, &matchedLine{
-			content: "/" + string(menu.query),
-		})
-		lns = append(lns, menu.matchedLns...)
-		menu.fillScreen(lns)
+		menu.fillScreen(menu.matchedLns)
 		menu.resetChosenLine()
+		menu.drawPreview()
 		cell := cellCnt(menu.query[:menu.inputCursorPos])
-		menu.screen.ShowCursor(cell+3, 1)
+		menu.screen.ShowCursor(cell+2+menu.calRuneWidth(menu.queryPrompt()), 1)
 	case modeI:
 		menu.screen.Clear()
-		lns := append([]string{":" + string(menu.input)}, menu.lines...)
-		menu.fillScreen(strSliceToMatchedLines(lns))
+		menu.fillScreen(menu.entries())
 		cell := cellCnt(menu.input[:menu.inputCursorPos])
 		menu.screen.ShowCursor(cell+3, 1)
 	}
@@ -163,23 +255,21 @@ func (menu *MenuScreen) refreshScreen() {
 // resetChosenLine re-draw the chosen line with cursor.
 func (menu *MenuScreen) resetChosenLine() {
 
-	if menu.mode == modeN {
-		// reset last chosen line
-		menu.setLineWithStyle(menu.lastCursorY+1, "  "+menu.lines[menu.lastCursorY], nil, defaultContentStyle)
-		// highlight the chosen line
-		menu.setLineWithStyle(menu.cursorY+1, "  "+menu.lines[menu.cursorY], nil, defaultChosenLineStyle)
-		// draw the cursor arrow
-		menu.setRuneOfLine(0, menu.cursorY+1, '▸', defaultChosenLineStyle)
+	if menu.mode == modeI {
 		return
 	}
+	entries := menu.entries()
 
-	if menu.mode == modeS {
-		if len(menu.matchedLns) > 0 && menu.cursorY < len(menu.matchedLns) {
-			ln := menu.matchedLns[menu.cursorY]
-			menu.setLineWithStyle(2+menu.cursorY, "  "+ln.content, ln.pos, defaultChosenLineStyle)
-			menu.setRuneOfLine(0, menu.cursorY+2, '▸', defaultChosenLineStyle)
+	// reset last chosen line, the whole screen is redrawn in the query mode
+	if menu.mode == modeN {
+		if y, ok := menu.rowOf(menu.lastCursorY, len(entries)); ok {
+			menu.drawEntry(y, entries[menu.lastCursorY], false)
 		}
-		return
+	}
+
+	// highlight the chosen line
+	if y, ok := menu.rowOf(menu.cursorY, len(entries)); ok && menu.selectable(entries[menu.cursorY]) {
+		menu.drawEntry(y, entries[menu.cursorY], true)
 	}
 
 }
@@ -187,33 +277,248 @@ func (menu *MenuScreen) resetChosenLine() {
 func (menu *MenuScreen) fillScreen(lines matchedLines) {
 
 	// title
-	menu.setLineWithStyle(0, menu.title, nil, defaultTitleStyle)
+	menu.setLineWithStyle(0, menu.title, nil, menu.theme.Title)
 
-	// content
-	for i, ln := range lines {
-		style := defaultContentStyle
-		if i == 0 && menu.mode == modeS {
-			style = defaultQueryStyle
+	// query or input line, it stays above the viewport
+	switch menu.mode {
+	case modeS:
+		menu.setLineWithStyle(1, "  "+menu.queryPrompt()+string(menu.query), nil, menu.theme.Query)
+	case modeI:
+		menu.setLineWithStyle(1, "  "+colon+string(menu.input), nil, menu.theme.Content)
+		if menu.inputErr != nil {
+			menu.setLineWithStyle(2, "  "+menu.inputErr.Error(), nil, menu.theme.Error)
 		}
-		menu.setLineWithStyle(i+1, "  "+ln.content, ln.pos, style)
-		// highlight the cursor column
-		if menu.mode == modeS && i == 0 {
-			continue
+	}
+
+	// header row of the table
+	top := menu.contentTop()
+	if menu.hasColumnHeaders() {
+		menu.setLineWithStyle(top-1, "  "+menu.columnHeaderText(), nil, menu.theme.Section)
+	}
+
+	// content, only the lines inside the viewport are drawn
+	end := min(len(lines), menu.offset+menu.pageSize())
+	for i := menu.offset; i < end; i++ {
+		menu.drawEntry(top+i-menu.offset, lines[i], false)
+	}
+
+	// header, footer, status line and key hints
+	menu.drawBars(menu.barRows())
+
+}
+
+// drawEntry draws the entry at the row y, with the cursor arrow if it's chosen.
+func (menu *MenuScreen) drawEntry(y int, ln *matchedLine, chosen bool) {
+	style := menu.theme.Content
+	if chosen {
+		style = menu.theme.ChosenLine
+	}
+
+	switch menu.kindOf(ln.idx) {
+	case ItemHeader, ItemSeparator:
+		if !chosen {
+			style = menu.theme.Section
+		}
+		menu.setLineWithStyle(y, "  "+menu.sectionText(ln), nil, style)
+	default:
+		if menu.disabled(ln.idx) || ln.context {
+			style = menu.theme.Disabled
+			if chosen {
+				style = menu.theme.ChosenLine.Dim(true)
+			}
+		}
+		lineStyle := style
+		style = menu.itemStyle(ln.idx, style)
+		content, pos, shift := ln.content, ln.pos, 0
+		if menu.columnsOf(ln.idx) != nil {
+			content, pos = menu.rowText(ln.idx, pos)
 		}
-		menu.screen.SetContent(0, i+1, ' ', nil, defaultCursorColStyle)
+		if menu.tree != nil {
+			guide := menu.treeText(ln)
+			shift = len([]rune(guide))
+			content, pos = guide+content, shiftPos(pos, shift)
+		}
+		spans := menu.itemSpans(ln.idx, shift)
+		if chosen {
+			// the chosen line is drawn over the styles of the item
+			spans = append(slices.Clip(spans), Span{Start: 0, End: len([]rune(content)), Style: lineStyle})
+			style = layerStyle(style, lineStyle)
+		}
+		menu.setStyledLine(y, "  "+content, pos, style, spans)
 	}
 
-	// statistic
-	statistic := fmt.Sprintf("%d/%d", len(menu.lines), len(menu.lines))
+	if chosen {
+		// draw the cursor arrow
+		menu.setRuneOfLine(0, y, '▸', menu.theme.ChosenLine)
+	} else {
+		// highlight the cursor column
+		menu.screen.SetContent(0, y, ' ', nil, menu.theme.CursorCol)
+	}
+	if menu.mode != modeI {
+		menu.drawMark(y, ln.idx, style)
+	}
+}
+
+// queryPrompt returns the prompt of the query line with the name of the matcher.
+func (menu *MenuScreen) queryPrompt() string {
+	return "[" + menu.matcher().Name() + "] " + slash
+}
+
+// drawMark draws the mark next to the cursor arrow if the line was marked.
+func (menu *MenuScreen) drawMark(y, idx int, style tcell.Style) {
+	if _, ok := menu.marked[idx]; ok {
+		menu.setRuneOfLine(1, y, markGlyph, style)
+	}
+}
+
+// contentTop returns the screen row of the first visible entry.
+func (menu *MenuScreen) contentTop() int {
+	top := 1 + len(menu.barsAt(BarTop))
+	// the header row of the table is right above the entries
+	if menu.hasColumnHeaders() {
+		top++
+	}
+	if menu.mode == modeN {
+		return top
+	}
+	// the status line of the validator is under the input line
+	if menu.mode == modeI && menu.validator != nil {
+		top++
+	}
+	// the query line or the input line takes the 2nd row
+	return top + 1
+}
+
+// pageSize returns how many entries fit between the top bars and the bottom bars.
+func (menu *MenuScreen) pageSize() int {
+	_, h := menu.screen.Size()
+	return max(1, h-menu.contentTop()-len(menu.barsAt(BarBottom))-menu.previewRows())
+}
+
+// barRows returns the row of the first top bar and the row of the first bottom bar,
+// the bottom bars follow the last visible entry.
+func (menu *MenuScreen) barRows() (top, bottom int) {
+	top = menu.contentTop() - len(menu.barsAt(BarTop))
+	if menu.hasColumnHeaders() {
+		top--
+	}
+	visible := max(0, min(menu.entryCount()-menu.offset, menu.pageSize()))
+	return top, menu.contentTop() + visible
+}
+
+// rowOf returns the screen row of the n-th entry, ok is false if it's out of the viewport.
+func (menu *MenuScreen) rowOf(n, total int) (y int, ok bool) {
+	if n < 0 || n >= total || n < menu.offset || n >= menu.offset+menu.pageSize() {
+		return 0, false
+	}
+	return menu.contentTop() + n - menu.offset, true
+}
+
+// entryCount returns the count of the entries in the list of the current mode.
+func (menu *MenuScreen) entryCount() int {
+	return len(menu.entries())
+}
+
+// entries returns the entries in the list of the current mode.
+func (menu *MenuScreen) entries() matchedLines {
 	if menu.mode == modeS {
-		statistic = fmt.Sprintf("%d/%d", len(menu.matchedLns), len(menu.lines))
+		return menu.matchedLns
 	}
-	menu.setLineWithStyle(len(lines)+1, statistic, nil, defaultContentStyle)
+	return menu.normalLines()
+}
 
+// normalLines returns the entries of the normal mode, which are all the lines
+// except the ones in the collapsed sections and under the collapsed nodes. They are cached until the lines
+// changed or a section was toggled.
+func (menu *MenuScreen) normalLines() matchedLines {
+	if menu.normalLns != nil && menu.normalCount == len(menu.lines) {
+		return menu.normalLns
+	}
+	menu.syncSections()
+	lns := make(matchedLines, 0, len(menu.lines))
+	for i, s := range menu.lines {
+		if !menu.hidden(i) {
+			lns = append(lns, &matchedLine{idx: i, content: s})
+		}
+	}
+	menu.attachItems(lns)
+	menu.layoutTree(lns)
+	menu.normalLns, menu.normalCount = lns, len(menu.lines)
+	return lns
+}
+
+// resetNormalLines drops the cached entries of the normal mode.
+func (menu *MenuScreen) resetNormalLines() {
+	menu.normalLns = nil
+	menu.heads = nil
+}
+
+// fixCursor moves the cursor onto a selectable entry, the next one first.
+func (menu *MenuScreen) fixCursor() {
+	entries := menu.entries()
+	menu.cursorY = max(0, min(menu.cursorY, len(entries)-1))
+	if len(entries) == 0 || menu.selectable(entries[menu.cursorY]) {
+		return
+	}
+	for i := menu.cursorY + 1; i < len(entries); i++ {
+		if menu.selectable(entries[i]) {
+			menu.cursorY = i
+			return
+		}
+	}
+	for i := menu.cursorY - 1; i >= 0; i-- {
+		if menu.selectable(entries[i]) {
+			menu.cursorY = i
+			return
+		}
+	}
+}
+
+// stepCursor returns the position of the next selectable entry in the
+// direction of step, it wraps around at the both ends.
+func (menu *MenuScreen) stepCursor(step int) (int, bool) {
+	entries := menu.entries()
+	n := len(entries)
+	for i, y := 0, menu.cursorY; i < n; i++ {
+		y = ((y+step)%n + n) % n
+		if menu.selectable(entries[y]) {
+			return y, true
+		}
+	}
+	return menu.cursorY, false
+}
+
+// scrollToCursor moves the viewport to make sure the cursor is visible.
+func (menu *MenuScreen) scrollToCursor() {
+	total := menu.entryCount()
+	size := menu.pageSize()
+	offset := menu.offset
+	if menu.cursorY < offset {
+		offset = menu.cursorY
+		// show the headers and separators right above the cursor
+		entries := menu.entries()
+		for offset > 0 && menu.cursorY-offset+1 < size && !menu.selectable(entries[offset-1]) {
+			offset--
+		}
+	} else if menu.cursorY >= offset+size {
+		offset = menu.cursorY - size + 1
+	}
+	// do not leave blank rows at the bottom when the screen grows
+	offset = max(0, min(offset, total-size))
+	if offset != menu.offset {
+		menu.offset = offset
+		menu.hasFilled = false
+	}
 }
 
 func (menu *MenuScreen) setLineWithStyle(y int, content string, hlPos []int, style tcell.Style) {
-	x := 0
+	menu.setStyledLine(y, content, hlPos, style, nil)
+}
+
+// setStyledLine draws the line with the spans over style, and the highlight
+// of hlPos over them, their positions start after the first 2 columns.
+func (menu *MenuScreen) setStyledLine(y int, content string, hlPos []int, style tcell.Style, spans []Span) {
+	x, maxX := 0, menu.listWidth()
 	pset := make(map[int]struct{})
 	for _, p := range hlPos {
 		pset[p] = struct{}{}
@@ -221,9 +526,12 @@ func (menu *MenuScreen) setLineWithStyle(y int, content string, hlPos []int, sty
 	pos := 0
 	for _, c := range content {
 		r, w, comb := menu.calRuneWidthAndComb(c)
-		targetStyle := style
+		if x+w > maxX {
+			break
+		}
+		targetStyle := spanStyle(spans, pos-2, style)
 		if _, ok := pset[pos-2]; ok {
-			targetStyle = defaultHighlightStyle
+			targetStyle = menu.theme.Highlight
 		}
 		menu.screen.SetContent(x, y, r, comb, targetStyle)
 		x += w
@@ -231,6 +539,13 @@ func (menu *MenuScreen) setLineWithStyle(y int, content string, hlPos []int, sty
 	}
 }
 
+// clearLine clears the row y of the list.
+func (menu *MenuScreen) clearLine(y int) {
+	for x := 0; x < menu.listWidth(); x++ {
+		menu.screen.SetContent(x, y, ' ', nil, menu.theme.Content)
+	}
+}
+
 func (menu *MenuScreen) setRuneOfLine(x, y int, c rune, style tcell.Style) {
 	r, _, comb := menu.calRuneWidthAndComb(c)
 	menu.screen.SetContent(x, y, r, comb, style)
@@ -17,11 +17,9 @@
 package menuscreen
 
 import (
-	"sort"
-	"strings"
+	"slices"
 
 	"github.com/gdamore/tcell/v2"
-	"github.com/sshelll/fzflib"
 )
 
 // This file includes all reserved key bind mapping.
@@ -31,40 +29,39 @@ import (
 // 'slash' means enter the query mode;
 // 'runes' means input in the query mode;
 // 'backspace' means rollback the last char from input;
+// 'ctrl-a ctrl-e ctrl-w ctrl-u ctrl-k ctrl-y alt-b alt-f' edit the query and input lines like readline;
+// 'tab' and 'shift-tab' toggle the mark of a line in multi-select mode;
+// 'shift-↑ shift-↓' scroll the preview pane;
+// 'ctrl-r' switches to the next matcher in the query mode;
+// 'ctrl-s' toggles sorting by score in the query mode;
+// 'space' in the normal mode and 'ctrl-space' collapse or expand the section;
+// '← →' and 'h l' in the normal mode collapse or expand the node of a tree;
+// 'ctrl-p ctrl-n' recall the history in the query mode and the input mode;
 
 func (menu *MenuScreen) keyUP(*tcell.EventKey) {
 	menu.lastCursorY = menu.cursorY
-	if menu.cursorY > 0 {
-		menu.cursorY--
-	} else if menu.mode == modeN {
-		menu.cursorY = len(menu.lines) - 1
-	} else if menu.mode == modeS {
-		menu.cursorY = len(menu.matchedLns) - 1
-	}
+	menu.cursorY, _ = menu.stepCursor(-1)
 }
 
 func (menu *MenuScreen) keyDOWN(*tcell.EventKey) {
 	menu.lastCursorY = menu.cursorY
-	if menu.checkCursor() {
-		menu.cursorY++
-	} else {
-		menu.cursorY = 0
-	}
+	menu.cursorY, _ = menu.stepCursor(1)
 }
 
 func (menu *MenuScreen) keyRIGHT(*tcell.EventKey) {
-	if menu.mode == modeI {
-		menu.inputCursorPos = min(menu.inputCursorPos+1, len(menu.input))
-	}
-	if menu.mode == modeS {
-		menu.inputCursorPos = min(menu.inputCursorPos+1, len(menu.query))
+	if menu.mode == modeN {
+		menu.keyEXPAND()
+		return
 	}
+	menu.moveCursor(menu.inputCursorPos + 1)
 }
 
 func (menu *MenuScreen) keyLEFT(*tcell.EventKey) {
-	if menu.mode == modeI || menu.mode == modeS {
-		menu.inputCursorPos = max(menu.inputCursorPos-1, 0)
+	if menu.mode == modeN {
+		menu.keyCOLLAPSE()
+		return
 	}
+	menu.moveCursor(menu.inputCursorPos - 1)
 }
 
 func (menu *MenuScreen) keyESC(*tcell.EventKey) {
@@ -80,83 +77,102 @@ func (menu *MenuScreen) keyESC(*tcell.EventKey) {
 }
 
 func (menu *MenuScreen) keyENTER(*tcell.EventKey) {
+	// the menu stays open until the input is valid
+	if menu.mode == modeI && !menu.validateInput() {
+		return
+	}
+	// there is nothing to choose, e.g. no line was matched
+	idx, ok := menu.cursorIdx()
+	if !ok && menu.mode != modeI && len(menu.markedIdxs()) == 0 {
+		return
+	}
+	// enter expands the collapsed section instead
+	if ok && menu.mode != modeI && menu.kindOf(idx) == ItemHeader {
+		menu.keyTOGGLESECTION(nil)
+		return
+	}
+	if ok && menu.mode != modeI && menu.disabled(idx) {
+		menu.message = menu.disabledReason(idx)
+		return
+	}
 	menu.confirmed = true
+	menu.saveHistory()
 	menu.inputCursorPos = 0
 	menu.shutdown()
 }
 
+// keyBS deletes the rune before the cursor.
 func (menu *MenuScreen) keyBS(*tcell.EventKey) {
+	menu.deleteRange(menu.inputCursorPos-1, menu.inputCursorPos)
+}
 
-	if menu.mode == modeI {
-		if len(menu.input) == 0 {
-			return
-		}
-		newRunes := []rune(menu.input)
-		delPos := max(0, menu.inputCursorPos-1)
-		delPos = min(delPos, len(newRunes)-1)
-		newRunes = append(newRunes[:delPos], newRunes[delPos+1:]...)
-		menu.input = newRunes
-		menu.inputCursorPos = max(menu.inputCursorPos-1, 0)
-		return
+// keyTAB toggles the mark of the current line and moves the cursor down.
+func (menu *MenuScreen) keyTAB(ev *tcell.EventKey) {
+	if menu.toggleMark() {
+		menu.keyDOWN(ev)
 	}
+}
 
-	if menu.mode == modeS {
-		if len(menu.query) == 0 {
-			return
-		}
-		newRunes := []rune(menu.query)
-		delPos := max(0, menu.inputCursorPos-1)
-		delPos = min(delPos, len(newRunes)-1)
-		newRunes = append(newRunes[:delPos], newRunes[delPos+1:]...)
-		menu.query = newRunes
-		menu.inputCursorPos = max(menu.inputCursorPos-1, 0)
-		menu.cursorY, menu.lastCursorY = 0, 0
-		menu.calMatchedLines()
+// keyBACKTAB toggles the mark of the current line and moves the cursor up.
+func (menu *MenuScreen) keyBACKTAB(ev *tcell.EventKey) {
+	if menu.toggleMark() {
+		menu.keyUP(ev)
 	}
+}
 
+// toggleMark marks or unmarks the line under the cursor by its original index,
+// so that the marks survive the changes of the query.
+func (menu *MenuScreen) toggleMark() bool {
+	if !menu.multiSelect || menu.mode == modeI {
+		return false
+	}
+	idx, ok := menu.cursorIdx()
+	if !ok || menu.kindOf(idx) != ItemEntry {
+		return false
+	}
+	if menu.disabled(idx) {
+		menu.message = menu.disabledReason(idx)
+		return false
+	}
+	if _, marked := menu.marked[idx]; marked {
+		delete(menu.marked, idx)
+	} else {
+		menu.marked[idx] = struct{}{}
+	}
+	// the mark of the line is drawn in the cursor column
+	menu.hasFilled = false
+	return true
+}
+
+// cursorIdx returns the original index of the line under the cursor.
+func (menu *MenuScreen) cursorIdx() (idx int, ok bool) {
+	if menu.mode == modeI {
+		return -1, false
+	}
+	entries := menu.entries()
+	if menu.cursorY < len(entries) && menu.selectable(entries[menu.cursorY]) {
+		return entries[menu.cursorY].idx, true
+	}
+	return -1, false
 }
 
 // keyRUNE controls the input of user.
 func (menu *MenuScreen) keyRUNE(ev *tcell.EventKey) {
+	menu.insertRunes([]rune{ev.Rune()})
+}
 
-	runeName := menu.getRuneName(ev.Name())
+// keyABORT exits MenuScreen without choosing anything.
+func (menu *MenuScreen) keyABORT(*tcell.EventKey) {
+	menu.shutdown()
+}
 
+// keyTOGGLESEARCH switches between the normal mode and the search mode.
+func (menu *MenuScreen) keyTOGGLESEARCH(ev *tcell.EventKey) {
 	if menu.mode == modeS {
-		newRunes := append(cloneRuneSlice(menu.query)[:menu.inputCursorPos], []rune(runeName)...)
-		newRunes = append(newRunes, []rune(menu.query)[menu.inputCursorPos:]...)
-		menu.query = newRunes
-		menu.calMatchedLines()
-		menu.inputCursorPos = min(menu.inputCursorPos+1, len(menu.query))
-		menu.cursorY = 0
+		menu.keyESC(ev)
 		return
 	}
-
-	if menu.mode == modeI {
-		newRunes := append(cloneRuneSlice(menu.input)[:menu.inputCursorPos], []rune(runeName)...)
-		newRunes = append(newRunes, []rune(menu.input)[menu.inputCursorPos:]...)
-		menu.input = newRunes
-		menu.inputCursorPos = min(menu.inputCursorPos+1, len(menu.input))
-		return
-	}
-
-	if menu.mode == modeN {
-		switch runeName {
-		case slash:
-			menu.keySLASH()
-		case colon:
-			menu.keyCOLON()
-		case "h":
-			menu.keyLEFT(ev)
-		case "j":
-			menu.keyDOWN(ev)
-		case "k":
-			menu.keyUP(ev)
-		case "l":
-			menu.keyRIGHT(ev)
-		}
-		return
-	}
-
+	menu.keySLASH()
 }
 
 // keySLASH key slash make MenuScreen enter search mode.
@@ -164,93 +180,170 @@ func (menu *MenuScreen) keySLASH() {
 	menu.mode = modeS
 	menu.query = nil
 	menu.cursorY = 0
-	menu.matchedLns = make([]*matchedLine, 0, len(menu.lines))
-	for i, ln := range menu.lines {
-		menu.matchedLns = append(menu.matchedLns, &matchedLine{
-			idx:     i,
-			content: ln,
-		})
-	}
+	menu.rewindHistory()
+	menu.calMatchedLines()
 }
 
 // keyCOLON key colon make MenuScreen enter insert mode.
 func (menu *MenuScreen) keyCOLON() {
 	menu.mode = modeI
 	menu.input = nil
+	menu.inputErr = nil
 	menu.cursorY = 0
+	menu.rewindHistory()
 }
 
-func (menu *MenuScreen) getRuneName(k string) string {
-	if !strings.HasPrefix(k, "Rune") {
-		return k
-	}
-	return strings.ReplaceAll(strings.ReplaceAll(k, "]", ""), "Rune[", "")
-}
+func (menu *MenuScreen) calMatchedLines() {
+	normal := menu.normalLines()
 
-func (menu *MenuScreen) checkCursor() bool {
-	if menu.mode == modeN {
-		return menu.cursorY < len(menu.lines)-1
+	// the list is the same as the normal mode until something was typed
+	if len(menu.query) == 0 && menu.effectiveSortOrder() != SortReverse {
+		menu.matchedLns = append(make(matchedLines, 0, len(normal)), normal...)
+		return
 	}
-	return menu.cursorY < len(menu.matchedLns)-1
-}
 
-func (menu *MenuScreen) calMatchedLines() {
-	matched := make([]*matchedLine, 0, len(menu.lines))
+	var results []MatchResult
 	if len(menu.query) == 0 {
-		for _, c := range menu.lines {
-			matched = append(matched, &matchedLine{content: c})
+		results = make([]MatchResult, 0, len(menu.lines))
+		for _, ln := range normal {
+			results = append(results, MatchResult{Index: ln.idx})
 		}
-		return
+	} else {
+		results = menu.search(string(menu.query))
 	}
 
-	fzf := menu.fuzzyFinder.Clear()
-	var results []*fzflib.MatchResult
-
-	if len(menu.items) == 0 {
-		// match by lines
-		results = fzf.AppendTargets(menu.lines...).MergeMatch(string(menu.query))
+	if menu.heads != nil {
+		// the headers and separators are not matched but shown above the matches
+		results = slices.DeleteFunc(results, func(r MatchResult) bool {
+			return menu.kindOf(r.Index) != ItemEntry
+		})
+	}
+	if len(menu.query) > 0 {
+		menu.sortResults(results)
 	} else {
-		// match by items
-		for _, item := range menu.items {
-			fzf.AppendItems(&fzflib.Item{
-				Content: item.Content,
-				Any:     item.Item,
-			})
-		}
-		results = fzf.MergeMatchItem(string(menu.query))
+		slices.Reverse(results)
 	}
-
-	// sort by score
-	sort.Slice(results, func(i, j int) bool {
-		return results[i].Score() < results[j].Score()
-	})
-
-	for i, ln := range results {
-		mln := &matchedLine{
-			idx:     i,
-			content: ln.Content(),
-			pos:     ln.Pos(),
-		}
-		if ln.Item() != nil {
-			mln.item = ln.Item().Any
-			mln.content = ln.Item().Content
-		}
-		matched = append(matched, mln)
+	results, context := menu.withAncestors(results)
+	results = menu.groupBySection(results)
+
+	matched := make(matchedLines, 0, len(results))
+	for _, r := range results {
+		matched = append(matched, &matchedLine{
+			idx:     r.Index,
+			content: menu.lines[r.Index],
+			pos:     r.Pos,
+			context: context[r.Index],
+		})
 	}
+	menu.attachItems(matched)
+	menu.layoutTree(matched)
 	menu.matchedLns = matched
 }
 
+// attachItems fills the item of each matched line in the item menu.
+func (menu *MenuScreen) attachItems(lns matchedLines) {
+	if len(menu.items) == 0 {
+		return
+	}
+	for _, ln := range lns {
+		ln.item = menu.items[ln.idx].Item
+	}
+}
+
 func (menu *MenuScreen) initKeyBinder() {
 
+	menu.actions = map[string]func(*tcell.EventKey){
+		"up":                   menu.keyUP,
+		"down":                 menu.keyDOWN,
+		"backward-char":        menu.keyLEFT,
+		"forward-char":         menu.keyRIGHT,
+		"backward-delete-char": menu.keyBS,
+		"delete-char":          menu.keyDELETE,
+		"beginning-of-line":    menu.keyBOL,
+		"end-of-line":          menu.keyEOL,
+		"backward-word":        menu.keyBACKWARDWORD,
+		"forward-word":         menu.keyFORWARDWORD,
+		"kill-word":            menu.keyKILLWORD,
+		"backward-kill-word":   menu.keyBACKWARDKILLWORD,
+		"unix-word-rubout":     menu.keyUNIXWORDRUBOUT,
+		"unix-line-discard":    menu.keyUNIXLINEDISCARD,
+		"kill-line":            menu.keyKILLLINE,
+		"yank":                 menu.keyYANK,
+		"accept":               menu.keyENTER,
+		"abort":                menu.keyABORT,
+		"cancel":               menu.keyESC,
+		"search":               func(*tcell.EventKey) { menu.keySLASH() },
+		"input":                func(*tcell.EventKey) { menu.keyCOLON() },
+		"toggle-search":        menu.keyTOGGLESEARCH,
+		"toggle":               func(*tcell.EventKey) { menu.toggleMark() },
+		"toggle+down":          menu.keyTAB,
+		"toggle+up":            menu.keyBACKTAB,
+		"cycle-matcher":        menu.keyCYCLEMATCHER,
+		"toggle-sort":          menu.keyTOGGLESORT,
+		"preview-up":           menu.keyPREVIEWUP,
+		"preview-down":         menu.keyPREVIEWDOWN,
+		"toggle-section":       menu.keyTOGGLESECTION,
+		"prev-history":         menu.keyPREVHISTORY,
+		"next-history":         menu.keyNEXTHISTORY,
+		"ignore":               func(*tcell.EventKey) {},
+	}
+
 	menu.keyBinder = new(keyBinder)
 
 	menu.keyBinder.bind(menu.keyUP, tcell.KeyUp)
 	menu.keyBinder.bind(menu.keyDOWN, tcell.KeyDown)
 	menu.keyBinder.bind(menu.keyENTER, tcell.KeyEnter)
 	menu.keyBinder.bind(menu.keyRUNE, tcell.KeyRune)
-	menu.keyBinder.bind(menu.keyBS, tcell.KeyBackspace, tcell.KeyDEL, tcell.KeyDelete)
+	menu.keyBinder.bind(menu.keyBS, tcell.KeyBackspace, tcell.KeyDEL)
+	menu.keyBinder.bind(menu.keyDELETE, tcell.KeyDelete)
 	menu.keyBinder.bind(menu.keyESC, tcell.KeyEsc)
 	menu.keyBinder.bind(menu.keyLEFT, tcell.KeyLeft)
 	menu.keyBinder.bind(menu.keyRIGHT, tcell.KeyRight)
+	menu.keyBinder.bind(menu.keyTAB, tcell.KeyTab)
+	menu.keyBinder.bind(menu.keyBACKTAB, tcell.KeyBacktab)
+	menu.keyBinder.bindSpec(menu.keyCYCLEMATCHER, newKeySpec(tcell.KeyCtrlR, tcell.ModCtrl, 0), modeS)
+	menu.keyBinder.bindSpec(menu.keyTOGGLESORT, newKeySpec(tcell.KeyCtrlS, tcell.ModCtrl, 0), modeS)
+	menu.bindEditingKeys()
+	menu.keyBinder.bindSpec(menu.keyPREVHISTORY, newKeySpec(tcell.KeyCtrlP, tcell.ModCtrl, 0), modeS, modeI)
+	menu.keyBinder.bindSpec(menu.keyNEXTHISTORY, newKeySpec(tcell.KeyCtrlN, tcell.ModCtrl, 0), modeS, modeI)
+	menu.keyBinder.bindSpec(menu.keyTOGGLESECTION, newKeySpec(tcell.KeyCtrlSpace, tcell.ModCtrl, 0))
+	menu.keyBinder.bindSpec(menu.keySHIFTUP, newKeySpec(tcell.KeyUp, tcell.ModShift, 0))
+	menu.keyBinder.bindSpec(menu.keySHIFTDOWN, newKeySpec(tcell.KeyDown, tcell.ModShift, 0))
+
+	// runes only work as commands in the normal mode
+	menu.keyBinder.bindRune(menu.actions["search"], modeN, '/')
+	menu.keyBinder.bindRune(menu.actions["input"], modeN, ':')
+	menu.keyBinder.bindRune(menu.keyLEFT, modeN, 'h')
+	menu.keyBinder.bindRune(menu.keyDOWN, modeN, 'j')
+	menu.keyBinder.bindRune(menu.keyUP, modeN, 'k')
+	menu.keyBinder.bindRune(menu.keyRIGHT, modeN, 'l')
+	menu.keyBinder.bindRune(menu.keyTOGGLESECTION, modeN, ' ')
+
+}
 
+// bindEditingKeys binds the readline-like keys in the query mode and the input mode.
+func (menu *MenuScreen) bindEditingKeys() {
+	bindings := []struct {
+		fn   func(*tcell.EventKey)
+		spec keySpec
+	}{
+		{menu.keyBOL, newKeySpec(tcell.KeyCtrlA, tcell.ModCtrl, 0)},
+		{menu.keyBOL, newKeySpec(tcell.KeyHome, tcell.ModNone, 0)},
+		{menu.keyEOL, newKeySpec(tcell.KeyCtrlE, tcell.ModCtrl, 0)},
+		{menu.keyEOL, newKeySpec(tcell.KeyEnd, tcell.ModNone, 0)},
+		{menu.keyLEFT, newKeySpec(tcell.KeyCtrlB, tcell.ModCtrl, 0)},
+		{menu.keyRIGHT, newKeySpec(tcell.KeyCtrlF, tcell.ModCtrl, 0)},
+		{menu.keyDELETE, newKeySpec(tcell.KeyCtrlD, tcell.ModCtrl, 0)},
+		{menu.keyBACKWARDWORD, newKeySpec(tcell.KeyRune, tcell.ModAlt, 'b')},
+		{menu.keyFORWARDWORD, newKeySpec(tcell.KeyRune, tcell.ModAlt, 'f')},
+		{menu.keyKILLWORD, newKeySpec(tcell.KeyRune, tcell.ModAlt, 'd')},
+		{menu.keyBACKWARDKILLWORD, newKeySpec(tcell.KeyBackspace2, tcell.ModAlt, 0)},
+		{menu.keyUNIXWORDRUBOUT, newKeySpec(tcell.KeyCtrlW, tcell.ModCtrl, 0)},
+		{menu.keyUNIXLINEDISCARD, newKeySpec(tcell.KeyCtrlU, tcell.ModCtrl, 0)},
+		{menu.keyKILLLINE, newKeySpec(tcell.KeyCtrlK, tcell.ModCtrl, 0)},
+		{menu.keyYANK, newKeySpec(tcell.KeyCtrlY, tcell.ModCtrl, 0)},
+	}
+	for _, b := range bindings {
+		menu.keyBinder.bindSpec(b.fn, b.spec, modeS, modeI)
+	}
 }
@@ -0,0 +1,46 @@
+package menuscreen
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestChosenLinesOfMarks(t *testing.T) {
+	menu := &MenuScreen{}
+	menu.SetLines("foo", "bar", "baz").SetMultiSelect(true)
+	for _, y := range []int{2, 1, 0, 1} {
+		menu.cursorY = y
+		if !menu.toggleMark() {
+			t.Fatalf("toggleMark failed at %d", y)
+		}
+	}
+	menu.confirmed = true
+
+	idxs, lns, ok := menu.ChosenLines()
+	if !ok || !slices.Equal(idxs, []int{0, 2}) || !slices.Equal(lns, []string{"foo", "baz"}) {
+		t.Fatalf("ChosenLines() = %v, %v, %v", idxs, lns, ok)
+	}
+}
+
+func TestChosenLinesWithoutMarks(t *testing.T) {
+	menu := &MenuScreen{}
+	menu.SetLines("foo", "bar").SetMultiSelect(true)
+	menu.cursorY = 1
+	if _, _, ok := menu.ChosenLines(); ok {
+		t.Fatal("ChosenLines() should fail before confirmed")
+	}
+	menu.confirmed = true
+
+	idxs, lns, ok := menu.ChosenLines()
+	if !ok || !slices.Equal(idxs, []int{1}) || !slices.Equal(lns, []string{"bar"}) {
+		t.Fatalf("ChosenLines() = %v, %v, %v", idxs, lns, ok)
+	}
+}
+
+func TestToggleMarkWithoutMultiSelect(t *testing.T) {
+	menu := &MenuScreen{}
+	menu.SetLines("foo")
+	if menu.toggleMark() {
+		t.Fatal("toggleMark should do nothing without the multi-select mode")
+	}
+}
@@ -0,0 +1,182 @@
+package menuscreen
+
+import (
+	"unicode"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// This file includes the readline-like editing of the query line and the
+// input line, they share the same editing core, which works on the line of
+// the current mode and the inputCursorPos.
+
+// editing reports whether the query line or the input line is being edited.
+func (menu *MenuScreen) editing() bool {
+	return menu.mode == modeS || menu.mode == modeI
+}
+
+// editLine returns the line being edited in the current mode.
+func (menu *MenuScreen) editLine() []rune {
+	if menu.mode == modeI {
+		return menu.input
+	}
+	return menu.query
+}
+
+// setEditLine replaces the line being edited and moves the cursor to pos,
+// then the lines are matched again in the query mode, or the input is
+// validated in the input mode.
+func (menu *MenuScreen) setEditLine(rs []rune, pos int) {
+	pos = max(0, min(pos, len(rs)))
+	switch menu.mode {
+	case modeS:
+		menu.query = rs
+		menu.inputCursorPos = pos
+		menu.cursorY, menu.lastCursorY = 0, 0
+		menu.calMatchedLines()
+	case modeI:
+		menu.input = rs
+		menu.inputCursorPos = pos
+		menu.validateInput()
+	}
+}
+
+// replaceLine replaces the line being edited and puts the cursor at the end.
+func (menu *MenuScreen) replaceLine(rs []rune) {
+	menu.setEditLine(rs, len(rs))
+}
+
+// insertRunes inserts rs at the cursor.
+func (menu *MenuScreen) insertRunes(rs []rune) {
+	if !menu.editing() || len(rs) == 0 {
+		return
+	}
+	line, pos := menu.editLine(), menu.inputCursorPos
+	newLine := make([]rune, 0, len(line)+len(rs))
+	newLine = append(newLine, line[:pos]...)
+	newLine = append(newLine, rs...)
+	newLine = append(newLine, line[pos:]...)
+	menu.setEditLine(newLine, pos+len(rs))
+}
+
+// deleteRange deletes the runes in [from, to) of the line being edited,
+// and returns the deleted ones.
+func (menu *MenuScreen) deleteRange(from, to int) []rune {
+	line := menu.editLine()
+	from, to = max(0, from), min(to, len(line))
+	if !menu.editing() || from >= to {
+		return nil
+	}
+	deleted := cloneRuneSlice(line[from:to])
+	newLine := make([]rune, 0, len(line)-len(deleted))
+	newLine = append(newLine, line[:from]...)
+	newLine = append(newLine, line[to:]...)
+	menu.setEditLine(newLine, from)
+	return deleted
+}
+
+// killRange deletes the runes in [from, to), and keeps them to be yanked.
+func (menu *MenuScreen) killRange(from, to int) {
+	if killed := menu.deleteRange(from, to); len(killed) > 0 {
+		menu.killed = killed
+	}
+}
+
+// moveCursor moves the cursor of the line being edited to pos.
+func (menu *MenuScreen) moveCursor(pos int) {
+	if menu.editing() {
+		menu.inputCursorPos = max(0, min(pos, len(menu.editLine())))
+	}
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// wordStart returns the start of the word before pos.
+func wordStart(line []rune, pos int) int {
+	for pos > 0 && !isWordRune(line[pos-1]) {
+		pos--
+	}
+	for pos > 0 && isWordRune(line[pos-1]) {
+		pos--
+	}
+	return pos
+}
+
+// wordEnd returns the end of the word after pos.
+func wordEnd(line []rune, pos int) int {
+	for pos < len(line) && !isWordRune(line[pos]) {
+		pos++
+	}
+	for pos < len(line) && isWordRune(line[pos]) {
+		pos++
+	}
+	return pos
+}
+
+// spaceWordStart returns the start of the space delimited word before pos.
+func spaceWordStart(line []rune, pos int) int {
+	for pos > 0 && unicode.IsSpace(line[pos-1]) {
+		pos--
+	}
+	for pos > 0 && !unicode.IsSpace(line[pos-1]) {
+		pos--
+	}
+	return pos
+}
+
+// keyDELETE deletes the rune under the cursor.
+func (menu *MenuScreen) keyDELETE(*tcell.EventKey) {
+	menu.deleteRange(menu.inputCursorPos, menu.inputCursorPos+1)
+}
+
+// keyBOL moves the cursor to the beginning of the line.
+func (menu *MenuScreen) keyBOL(*tcell.EventKey) {
+	menu.moveCursor(0)
+}
+
+// keyEOL moves the cursor to the end of the line.
+func (menu *MenuScreen) keyEOL(*tcell.EventKey) {
+	menu.moveCursor(len(menu.editLine()))
+}
+
+// keyBACKWARDWORD moves the cursor to the start of the previous word.
+func (menu *MenuScreen) keyBACKWARDWORD(*tcell.EventKey) {
+	menu.moveCursor(wordStart(menu.editLine(), menu.inputCursorPos))
+}
+
+// keyFORWARDWORD moves the cursor to the end of the next word.
+func (menu *MenuScreen) keyFORWARDWORD(*tcell.EventKey) {
+	menu.moveCursor(wordEnd(menu.editLine(), menu.inputCursorPos))
+}
+
+// keyKILLWORD kills the runes from the cursor to the end of the next word.
+func (menu *MenuScreen) keyKILLWORD(*tcell.EventKey) {
+	menu.killRange(menu.inputCursorPos, wordEnd(menu.editLine(), menu.inputCursorPos))
+}
+
+// keyBACKWARDKILLWORD kills the runes from the start of the previous word to the cursor.
+func (menu *MenuScreen) keyBACKWARDKILLWORD(*tcell.EventKey) {
+	menu.killRange(wordStart(menu.editLine(), menu.inputCursorPos), menu.inputCursorPos)
+}
+
+// keyUNIXWORDRUBOUT kills the runes from the previous space to the cursor.
+func (menu *MenuScreen) keyUNIXWORDRUBOUT(*tcell.EventKey) {
+	menu.killRange(spaceWordStart(menu.editLine(), menu.inputCursorPos), menu.inputCursorPos)
+}
+
+// keyUNIXLINEDISCARD kills the runes from the beginning of the line to the cursor.
+func (menu *MenuScreen) keyUNIXLINEDISCARD(*tcell.EventKey) {
+	menu.killRange(0, menu.inputCursorPos)
+}
+
+// keyKILLLINE kills the runes from the cursor to the end of the line.
+func (menu *MenuScreen) keyKILLLINE(*tcell.EventKey) {
+	menu.killRange(menu.inputCursorPos, len(menu.editLine()))
+}
+
+// keyYANK inserts the last killed runes at the cursor.
+func (menu *MenuScreen) keyYANK(*tcell.EventKey) {
+	menu.insertRunes(menu.killed)
+}
@@ -0,0 +1,32 @@
+package menuscreen
+
+import "testing"
+
+func TestWordBoundaries(t *testing.T) {
+	tests := []struct {
+		line                   string
+		pos                    int
+		start, end, spaceStart int
+	}{
+		{"", 0, 0, 0, 0},
+		{"foo bar", 7, 4, 7, 4},
+		{"foo bar", 4, 0, 7, 0},
+		{"foo bar", 3, 0, 7, 0},
+		{"foo bar", 0, 0, 3, 0},
+		{"foo.bar baz", 7, 4, 11, 0},
+		{"foo.bar  ", 9, 4, 9, 0},
+		{"世界 ok", 2, 0, 5, 0},
+	}
+	for _, tt := range tests {
+		line := []rune(tt.line)
+		if got := wordStart(line, tt.pos); got != tt.start {
+			t.Errorf("wordStart(%q, %d) = %d, want %d", tt.line, tt.pos, got, tt.start)
+		}
+		if got := wordEnd(line, tt.pos); got != tt.end {
+			t.Errorf("wordEnd(%q, %d) = %d, want %d", tt.line, tt.pos, got, tt.end)
+		}
+		if got := spaceWordStart(line, tt.pos); got != tt.spaceStart {
+			t.Errorf("spaceWordStart(%q, %d) = %d, want %d", tt.line, tt.pos, got, tt.spaceStart)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package menuscreen
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// DefaultHistorySize is the max count of the entries kept in a history file.
+const DefaultHistorySize = 1000
+
+// history records the confirmed queries and inputs.
+type history struct {
+	path    string
+	size    int
+	entries []string
+	// pos is the index of the entry being shown,
+	// len(entries) means the user is not browsing the history.
+	pos int
+	// editing is the line before browsing the history.
+	editing []rune
+}
+
+// SetHistoryFile enables the history of the confirmed queries and inputs,
+// which will be appended to the file at path once the menu was confirmed.
+// Press ctrl-p / ctrl-n in the query line or the input line to recall them.
+// The file can be shared by several processes.
+func (menu *MenuScreen) SetHistoryFile(path string) *MenuScreen {
+	if path == "" {
+		menu.history = nil
+		return menu
+	}
+	size := menu.historySize
+	if size <= 0 {
+		size = DefaultHistorySize
+	}
+	menu.history = &history{path: path, size: size}
+	menu.history.entries, _ = readHistory(path)
+	menu.history.rewind()
+	return menu
+}
+
+// SetHistorySize sets the max count of the entries kept in the history file,
+// the oldest ones will be dropped, DefaultHistorySize by default.
+// It can be called before or after SetHistoryFile.
+func (menu *MenuScreen) SetHistorySize(size int) *MenuScreen {
+	if size <= 0 {
+		return menu
+	}
+	menu.historySize = size
+	if menu.history != nil {
+		menu.history.size = size
+	}
+	return menu
+}
+
+// rewind stops browsing the history.
+func (h *history) rewind() {
+	h.pos = len(h.entries)
+	h.editing = nil
+}
+
+// add appends the entry to the history file, the same entry recorded
+// before will be removed, so that the entries are de-duplicated.
+func (h *history) add(entry string) error {
+	if strings.TrimSpace(entry) == "" || strings.ContainsAny(entry, "\r\n") {
+		return nil
+	}
+
+	unlock, err := lockHistory(h.path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	// read the file again, it might be changed by other processes
+	entries, err := readHistory(h.path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	entries = appendHistory(entries, entry, h.size)
+
+	if err = writeHistory(h.path, entries); err != nil {
+		return err
+	}
+	h.entries = entries
+	h.rewind()
+	return nil
+}
+
+// appendHistory appends the entry to the end of entries without duplicates,
+// and keeps at most size entries.
+func appendHistory(entries []string, entry string, size int) []string {
+	result := make([]string, 0, len(entries)+1)
+	for _, e := range entries {
+		if e != entry {
+			result = append(result, e)
+		}
+	}
+	result = append(result, entry)
+	if len(result) > size {
+		result = result[len(result)-size:]
+	}
+	return result
+}
+
+func readHistory(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if ln := scanner.Text(); ln != "" {
+			entries = append(entries, ln)
+		}
+	}
+	return entries, scanner.Err()
+}
+
+// writeHistory replaces the history file by renaming a temp file,
+// so the readers never see a half-written file.
+func writeHistory(path string, entries []string) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	w := bufio.NewWriter(tmp)
+	for _, e := range entries {
+		w.WriteString(e)
+		w.WriteByte('\n')
+	}
+	if err = w.Flush(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// saveHistory records the confirmed query or input.
+func (menu *MenuScreen) saveHistory() {
+	if menu.history == nil {
+		return
+	}
+	switch menu.mode {
+	case modeS:
+		// the history is optional, failing to write it should not
+		// break the menu, so the error is ignored here.
+		_ = menu.history.add(string(menu.query))
+	case modeI:
+		_ = menu.history.add(string(menu.input))
+	}
+}
+
+// rewindHistory stops browsing the history when entering a new line.
+func (menu *MenuScreen) rewindHistory() {
+	if menu.history != nil {
+		menu.history.rewind()
+	}
+}
+
+// keyPREVHISTORY replaces the query line or the input line by the previous
+// entry of the history.
+func (menu *MenuScreen) keyPREVHISTORY(*tcell.EventKey) {
+	h := menu.history
+	if h == nil || h.pos == 0 || (menu.mode != modeS && menu.mode != modeI) {
+		return
+	}
+	if h.pos == len(h.entries) {
+		h.editing = cloneRuneSlice(menu.editLine())
+	}
+	h.pos--
+	menu.replaceLine([]rune(h.entries[h.pos]))
+}
+
+// keyNEXTHISTORY replaces the query line or the input line by the next
+// entry of the history, or the line before browsing at the end.
+func (menu *MenuScreen) keyNEXTHISTORY(*tcell.EventKey) {
+	h := menu.history
+	if h == nil || h.pos >= len(h.entries) || (menu.mode != modeS && menu.mode != modeI) {
+		return
+	}
+	h.pos++
+	if h.pos == len(h.entries) {
+		menu.replaceLine(h.editing)
+		h.editing = nil
+		return
+	}
+	menu.replaceLine([]rune(h.entries[h.pos]))
+}
@@ -0,0 +1,42 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd)
+
+package menuscreen
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// staleLockTimeout is how long a lock file can be kept before it's
+// considered to be left by a dead process.
+const staleLockTimeout = 10 * time.Second
+
+// lockHistory takes an exclusive lock of the history file by creating a
+// lock file, since flock is not available on this platform.
+func lockHistory(path string) (unlock func(), err error) {
+	if err = os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	lock := path + ".lock"
+	deadline := time.Now().Add(staleLockTimeout)
+	for {
+		f, err := os.OpenFile(lock, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0o600)
+		if err == nil {
+			f.Close()
+			return func() { os.Remove(lock) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, err
+		}
+		if fi, err := os.Stat(lock); err == nil && time.Since(fi.ModTime()) > staleLockTimeout {
+			os.Remove(lock)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, errors.New("timed out waiting for the lock of " + path)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
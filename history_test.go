@@ -0,0 +1,91 @@
+package menuscreen
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestAppendHistory(t *testing.T) {
+	tests := []struct {
+		entries []string
+		entry   string
+		size    int
+		want    []string
+	}{
+		{nil, "a", 3, []string{"a"}},
+		{[]string{"a", "b"}, "c", 3, []string{"a", "b", "c"}},
+		// the same entry moves to the end
+		{[]string{"a", "b", "c"}, "a", 3, []string{"b", "c", "a"}},
+		{[]string{"a", "b", "c"}, "c", 3, []string{"a", "b", "c"}},
+		// the oldest entries are dropped
+		{[]string{"a", "b", "c"}, "d", 3, []string{"b", "c", "d"}},
+		{[]string{"a", "b", "c"}, "d", 1, []string{"d"}},
+	}
+	for _, tt := range tests {
+		in := slices.Clone(tt.entries)
+		if got := appendHistory(in, tt.entry, tt.size); !slices.Equal(got, tt.want) {
+			t.Errorf("appendHistory(%q, %q, %d) = %q, want %q", tt.entries, tt.entry, tt.size, got, tt.want)
+		}
+		if !slices.Equal(in, tt.entries) {
+			t.Errorf("appendHistory(%q, %q, %d) modified the entries", tt.entries, tt.entry, tt.size)
+		}
+	}
+}
+
+func TestHistoryAdd(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dir", "history")
+	h := &history{path: path, size: 2}
+	for _, e := range []string{"foo", " ", "multi\nline", "bar", "foo", "baz"} {
+		if err := h.add(e); err != nil {
+			t.Fatalf("add(%q): %v", e, err)
+		}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "foo\nbaz\n" {
+		t.Fatalf("the history file is %q", data)
+	}
+	if !slices.Equal(h.entries, []string{"foo", "baz"}) || h.pos != 2 {
+		t.Fatalf("the history is %q at %d", h.entries, h.pos)
+	}
+}
+
+// TestHistoryAddConcurrently checks that the processes sharing a history file do
+// not lose the entries of each other, the histories stand for the processes here.
+func TestHistoryAddConcurrently(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history")
+	var (
+		wg   sync.WaitGroup
+		want []string
+	)
+	for i := 0; i < 8; i++ {
+		entry := fmt.Sprintf("entry %d", i)
+		want = append(want, entry)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			h := &history{path: path, size: DefaultHistorySize}
+			if err := h.add(entry); err != nil {
+				t.Errorf("add(%q): %v", entry, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	slices.Sort(got)
+	if !slices.Equal(got, want) {
+		t.Fatalf("the history file has %q, want %q", got, want)
+	}
+}
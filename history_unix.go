@@ -0,0 +1,31 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+
+package menuscreen
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// lockHistory takes an exclusive lock of the history file, which blocks
+// until the other processes release it.
+// The lock is held on a separate file, because the history file itself
+// is replaced by renaming.
+func lockHistory(path string) (unlock func(), err error) {
+	if err = os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path+".lock", os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	if err = syscall.Flock(int(f.Fd()), syscall.LOCK_EX); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return func() {
+		syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+		f.Close()
+	}, nil
+}
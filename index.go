@@ -0,0 +1,175 @@
+package menuscreen
+
+import (
+	"strings"
+
+	"github.com/sshelll/fzflib/util"
+)
+
+// searchIndex keeps the lines converted for matching, and the results of the
+// recent queries, so that typing does not re-match all the lines every time.
+// The lines can only be appended while the index is alive, any other change
+// to them should reset the index, see resetSearchIndex.
+type searchIndex struct {
+	chars []util.Chars
+	// results of the recent queries, they are the prefixes of the last query.
+	results map[string]*cachedResults
+	// the results are only valid with the same matcher and syntax,
+	// matcherGen tells whether the matcher changed.
+	matcherGen int
+	plain      bool
+}
+
+type cachedResults struct {
+	// count is the count of the lines which have been matched,
+	// the lines appended later should be matched before using the results.
+	count   int
+	results []MatchResult
+}
+
+// indexedMatcher is implemented by the matchers which can match on the index directly.
+type indexedMatcher interface {
+	matchIndex(query string, chars []util.Chars, cands []int) []MatchResult
+}
+
+// narrowingMatcher is implemented by the matchers whose results of a query
+// always include the results of the longer queries starting with it.
+type narrowingMatcher interface {
+	narrows() bool
+}
+
+func (*algoMatcher) narrows() bool {
+	return true
+}
+
+// resetSearchIndex drops the index, it's called when the lines changed.
+func (menu *MenuScreen) resetSearchIndex() {
+	menu.index = nil
+}
+
+// searchIndex returns the index which is synced with the lines and the matcher.
+func (menu *MenuScreen) searchIndex() *searchIndex {
+	if menu.index == nil {
+		menu.index = &searchIndex{}
+	}
+	idx := menu.index
+	targets := menu.searchTargets()
+	for i := len(idx.chars); i < len(targets); i++ {
+		idx.chars = append(idx.chars, util.ToChars([]byte(targets[i])))
+	}
+	if idx.results == nil || idx.matcherGen != menu.matcherGen || idx.plain != menu.plainSearch {
+		idx.results = make(map[string]*cachedResults)
+		idx.matcherGen = menu.matcherGen
+		idx.plain = menu.plainSearch
+	}
+	return idx
+}
+
+// search returns the results of the query, the results of the previous queries
+// are reused if possible. The returned slice can be modified by the caller.
+func (menu *MenuScreen) search(query string) []MatchResult {
+	idx := menu.searchIndex()
+	n := len(menu.lines)
+
+	// shrinking the query, or nothing changed but new lines appended
+	if c, ok := idx.results[query]; ok {
+		if c.count < n {
+			c.results = append(c.results, menu.match(query, rangeIdxs(c.count, n))...)
+			c.count = n
+		}
+		idx.keepPrefixesOf(query)
+		return cloneResults(c.results)
+	}
+
+	// extending the query, only the previous results need to be matched
+	var cands []int
+	if base := idx.narrowingBase(query, menu.canNarrow); base != nil {
+		cands = make([]int, 0, len(base.results)+n-base.count)
+		for _, r := range base.results {
+			cands = append(cands, r.Index)
+		}
+		cands = append(cands, rangeIdxs(base.count, n)...)
+	}
+
+	results := menu.match(query, cands)
+	idx.keepPrefixesOf(query)
+	idx.results[query] = &cachedResults{count: n, results: results}
+	return cloneResults(results)
+}
+
+// canNarrow tells whether the results of the query can be narrowed down
+// from the results of its prefix.
+func (menu *MenuScreen) canNarrow(prefix, query string) bool {
+	if nm, ok := menu.matcher().(narrowingMatcher); !ok || !nm.narrows() {
+		return false
+	}
+	if menu.plainSearch {
+		return true
+	}
+	// negation, OR, suffix and escape of the extended syntax may widen the results
+	if strings.ContainsAny(query, `!|$\`) {
+		return false
+	}
+	// a single ' or ^ is matched as is, but it turns into the syntax once followed by more chars
+	last := prefix[strings.LastIndexByte(prefix, ' ')+1:]
+	return last != "'" && last != "^"
+}
+
+// narrowingBase returns the results of the longest cached prefix of the query,
+// which can be narrowed down to the results of the query.
+func (idx *searchIndex) narrowingBase(query string, canNarrow func(prefix, query string) bool) *cachedResults {
+	var (
+		base    *cachedResults
+		baseLen = -1
+	)
+	for q, c := range idx.results {
+		if len(q) > baseLen && len(q) < len(query) && strings.HasPrefix(query, q) && canNarrow(q, query) {
+			base, baseLen = c, len(q)
+		}
+	}
+	return base
+}
+
+// keepPrefixesOf drops the cached results which are not the prefixes of the query.
+func (idx *searchIndex) keepPrefixesOf(query string) {
+	for q := range idx.results {
+		if !strings.HasPrefix(query, q) {
+			delete(idx.results, q)
+		}
+	}
+}
+
+// matchOn matches the query on the candidates by m, or all the lines if cands is nil.
+// The indexes of the results are the original indexes of the lines.
+func (menu *MenuScreen) matchOn(m Matcher, query string, cands []int) []MatchResult {
+	if im, ok := m.(indexedMatcher); ok {
+		return im.matchIndex(query, menu.searchIndex().chars, cands)
+	}
+	all := menu.searchTargets()
+	if cands == nil {
+		return m.Match(query, all)
+	}
+	targets := make([]string, 0, len(cands))
+	for _, i := range cands {
+		targets = append(targets, all[i])
+	}
+	results := m.Match(query, targets)
+	for i := range results {
+		results[i].Index = cands[results[i].Index]
+	}
+	return results
+}
+
+func rangeIdxs(start, end int) []int {
+	idxs := make([]int, 0, max(0, end-start))
+	for i := start; i < end; i++ {
+		idxs = append(idxs, i)
+	}
+	return idxs
+}
+
+func cloneResults(results []MatchResult) []MatchResult {
+	dst := make([]MatchResult, len(results))
+	copy(dst, results)
+	return dst
+}
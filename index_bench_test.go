@@ -0,0 +1,53 @@
+package menuscreen
+
+import (
+	"fmt"
+	"testing"
+)
+
+func newBenchMenu(n int) *MenuScreen {
+	words := []string{"service", "api", "gateway", "worker", "cron", "db", "cache", "frontend"}
+	regions := []string{"us-east-1", "eu-west-2", "ap-south-1", "cn-north-1"}
+	lines := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		lines = append(lines, fmt.Sprintf("%s-%s-%d %s",
+			words[i%len(words)], words[(i/len(words))%len(words)], i, regions[i%len(regions)]))
+	}
+	return &MenuScreen{lines: lines, matchers: defaultMatchers()}
+}
+
+// benchmarkTyping types the query char by char, deletes a few chars and types again,
+// just like what a user does in the search mode.
+func benchmarkTyping(b *testing.B, n int) {
+	menu := newBenchMenu(n)
+	query := []rune("apiwork")
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		menu.resetSearchIndex()
+		menu.query = nil
+		for _, r := range query {
+			menu.query = append(menu.query, r)
+			menu.calMatchedLines()
+		}
+		for j := 0; j < 3; j++ {
+			menu.query = menu.query[:len(menu.query)-1]
+			menu.calMatchedLines()
+		}
+		for _, r := range query[len(query)-3:] {
+			menu.query = append(menu.query, r)
+			menu.calMatchedLines()
+		}
+	}
+}
+
+func BenchmarkTyping10K(b *testing.B) {
+	benchmarkTyping(b, 10_000)
+}
+
+func BenchmarkTyping100K(b *testing.B) {
+	benchmarkTyping(b, 100_000)
+}
+
+func BenchmarkTyping1M(b *testing.B) {
+	benchmarkTyping(b, 1_000_000)
+}
@@ -0,0 +1,111 @@
+package menuscreen
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+// sortedResults returns the results in the order of the indexes,
+// with the positions sorted, so that they can be compared.
+func sortedResults(results []MatchResult) []MatchResult {
+	sorted := make([]MatchResult, 0, len(results))
+	for _, r := range results {
+		r.Pos = slices.Clone(r.Pos)
+		slices.Sort(r.Pos)
+		sorted = append(sorted, r)
+	}
+	slices.SortFunc(sorted, func(a, b MatchResult) int {
+		return a.Index - b.Index
+	})
+	return sorted
+}
+
+func equalResults(a, b []MatchResult) bool {
+	return slices.EqualFunc(sortedResults(a), sortedResults(b), func(x, y MatchResult) bool {
+		return x.Index == y.Index && x.Score == y.Score && slices.Equal(x.Pos, y.Pos)
+	})
+}
+
+func TestSearchCache(t *testing.T) {
+	var lines []string
+	for _, a := range []string{"main", "Makefile", "map", "i'm", "a b"} {
+		for _, b := range []string{".go", "_test.go", ".md", "^x", "|y"} {
+			lines = append(lines, a+b)
+		}
+	}
+	menu := newTestMenu(t, lines[:20]...)
+
+	// each step changes the menu, and then the query is typed char by char and deleted
+	steps := []struct {
+		name  string
+		query string
+		do    func()
+	}{
+		{"fuzzy", "main.go", nil},
+		{"typo", "maxn", nil},
+		{"case", "Make", nil},
+		{"extended", "ma !test", nil},
+		{"quote", "'i'm", nil},
+		{"prefix", "^ma go$", nil},
+		{"or", "md | te", nil},
+		{"escape", `a\ b`, nil},
+		{"appended", "main", func() { menu.lines = append(menu.lines, lines[20:]...) }},
+		{"exact", "ma.g", func() { menu.SetMatchers(ExactMatcher(), FuzzyMatcher()) }},
+		{"cycled", "ma.g", func() { menu.keyCYCLEMATCHER(nil) }},
+		{"regex", "^ma+.", func() { menu.SetMatcher(RegexMatcher()) }},
+		{"plain", "ma !test", func() { menu.SetMatcher(FuzzyMatcher()).SetExtendedSearch(false) }},
+	}
+	for _, step := range steps {
+		if step.do != nil {
+			step.do()
+		}
+		var queries []string
+		for i := 1; i <= len(step.query); i++ {
+			queries = append(queries, step.query[:i])
+		}
+		for i := len(step.query) - 1; i >= 0; i-- {
+			queries = append(queries, step.query[:i])
+		}
+		for _, q := range queries {
+			fresh := newTestMenu(t, menu.lines...).SetMatcher(menu.matcher()).SetExtendedSearch(!menu.plainSearch)
+			if got, want := menu.search(q), fresh.search(q); !equalResults(got, want) {
+				t.Fatalf("%s: search(%q) = %v, want %v", step.name, q, sortedResults(got), sortedResults(want))
+			}
+		}
+	}
+}
+
+// wordsMatcher matches the lines containing any of the words, whatever the query is.
+// It's not comparable because of the slice field.
+type wordsMatcher struct {
+	words []string
+}
+
+func (wordsMatcher) Name() string {
+	return "words"
+}
+
+func (m wordsMatcher) Match(_ string, targets []string) []MatchResult {
+	var results []MatchResult
+	for i, t := range targets {
+		for _, w := range m.words {
+			if strings.Contains(t, w) {
+				results = append(results, MatchResult{Index: i})
+				break
+			}
+		}
+	}
+	return results
+}
+
+func TestSearchCacheOfUncomparableMatchers(t *testing.T) {
+	menu := newTestMenu(t, "foo", "bar").SetMatcher(wordsMatcher{words: []string{"foo"}})
+	if got := menu.search("x"); len(got) != 1 || got[0].Index != 0 {
+		t.Fatalf("search(x) = %v", got)
+	}
+	menu.SetMatcher(wordsMatcher{words: []string{"bar"}})
+	if got := menu.search("x"); len(got) != 1 || got[0].Index != 1 {
+		t.Fatalf("the results of the old matcher were reused: %v", got)
+	}
+}
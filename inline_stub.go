@@ -0,0 +1,10 @@
+//go:build !(aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris || zos)
+
+package menuscreen
+
+import "errors"
+
+// NewInlineMenuScreen is not supported on this platform.
+func NewInlineMenuScreen(height int) (*MenuScreen, error) {
+	return nil, errors.New("inline menu is not supported on this platform")
+}
@@ -0,0 +1,98 @@
+//go:build aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris || zos
+
+package menuscreen
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/gdamore/tcell/v2/terminfo"
+)
+
+// NewInlineMenuScreen creates a MenuScreen which is drawn in the next height
+// rows below the current cursor, instead of taking over the whole terminal.
+// The alternate screen is not used, so the scrollback stays intact, and the
+// rows will be cleaned up by Fini.
+//
+// NOTE: the terminal must support saving and restoring the cursor position by
+// 'CSI s' and 'CSI u', which are supported by almost all the modern terminals.
+func NewInlineMenuScreen(height int) (menuScreen *MenuScreen, err error) {
+
+	if height < 3 {
+		return nil, errors.New("height of the inline menu should be at least 3")
+	}
+
+	ti, err := tcell.LookupTerminfo(os.Getenv("TERM"))
+	if err != nil {
+		return
+	}
+
+	tty, err := tcell.NewDevTty()
+	if err != nil {
+		return
+	}
+
+	screen, err := tcell.NewTerminfoScreenFromTtyTerminfo(&inlineTty{Tty: tty, height: height}, inlineTerminfo(ti))
+	if err != nil {
+		return
+	}
+
+	return NewMenuScreenWithScreen(screen)
+
+}
+
+// inlineTerminfo derives a terminfo which draws relative to the saved cursor position.
+func inlineTerminfo(ti *terminfo.Terminfo) *terminfo.Terminfo {
+	inline := *ti
+	// stay on the main screen
+	inline.EnterCA = ""
+	inline.ExitCA = ""
+	// only clear the rows of the menu, they are the last rows of the terminal
+	inline.Clear = "\x1b[u\x1b[J"
+	// move to (col, row) of the menu, CSI B is skipped for the 1st row because
+	// 'CSI 0 B' moves the cursor down by 1 row in most terminals.
+	inline.SetCursor = "\x1b[u%?%p1%t\x1b[%p1%dB%;\x1b[%p2%{1}%+%dG"
+	return &inline
+}
+
+// inlineTty reserves the rows of the menu once it was started, and limits the
+// height of the window to the reserved rows.
+type inlineTty struct {
+	tcell.Tty
+	height   int
+	reserved bool
+}
+
+func (tty *inlineTty) Start() error {
+	if err := tty.Tty.Start(); err != nil {
+		return err
+	}
+	if tty.reserved {
+		return nil
+	}
+	tty.reserved = true
+	_, h, err := tty.Tty.WindowSize()
+	if err == nil && h > 0 {
+		tty.height = min(tty.height, h)
+	}
+	// scroll the terminal if there are not enough rows below the cursor,
+	// then move back to the 1st row of the menu and save the position.
+	rows := tty.height - 1
+	seq := "\r" + strings.Repeat("\n", rows)
+	if rows > 0 {
+		seq += fmt.Sprintf("\x1b[%dA", rows)
+	}
+	_, err = tty.Write([]byte(seq + "\x1b[s"))
+	return err
+}
+
+func (tty *inlineTty) WindowSize() (width int, height int, err error) {
+	width, height, err = tty.Tty.WindowSize()
+	if err != nil {
+		return
+	}
+	return width, min(height, tty.height), nil
+}
@@ -18,35 +18,331 @@ package menuscreen
 
 import (
 	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
 	"github.com/gdamore/tcell/v2"
 )
 
+// keySpec identifies a key press, r only makes sense when key is tcell.KeyRune,
+// and r == 0 means any rune.
+type keySpec struct {
+	key tcell.Key
+	mod tcell.ModMask
+	r   rune
+}
+
+func newKeySpec(key tcell.Key, mod tcell.ModMask, r rune) keySpec {
+	if key != tcell.KeyRune {
+		r = 0
+	}
+	switch key {
+	case tcell.KeyBackspace:
+		// some terminals send BS while others send DEL for the backspace key
+		key = tcell.KeyBackspace2
+		mod &^= tcell.ModCtrl
+	case tcell.KeyTab, tcell.KeyEsc, tcell.KeyEnter:
+		// ctrl-i, ctrl-[ and ctrl-m are the same as these keys
+		mod &^= tcell.ModCtrl
+	}
+	return keySpec{key: key, mod: mod, r: r}
+}
+
 type keyBinder struct {
-	mapping map[tcell.Key]func(*tcell.EventKey)
+	mapping     map[keySpec]func(*tcell.EventKey)
+	modeMapping map[Mode]map[keySpec]func(*tcell.EventKey)
 }
 
+// bind binds the keys to fn for all modes, an existing binding will be replaced.
 func (kb *keyBinder) bind(fn func(*tcell.EventKey), keys ...tcell.Key) *keyBinder {
+	for _, k := range keys {
+		kb.bindSpec(fn, newKeySpec(k, tcell.ModNone, 0))
+	}
+	return kb
+}
+
+// bindRune binds the runes to fn for the given mode, an existing binding will be replaced.
+func (kb *keyBinder) bindRune(fn func(*tcell.EventKey), mode Mode, runes ...rune) *keyBinder {
+	for _, r := range runes {
+		kb.bindSpec(fn, newKeySpec(tcell.KeyRune, tcell.ModNone, r), mode)
+	}
+	return kb
+}
 
+// bindSpec binds the spec to fn for the given modes, or all modes if no mode was given.
+func (kb *keyBinder) bindSpec(fn func(*tcell.EventKey), spec keySpec, modes ...Mode) {
 	if kb.mapping == nil {
-		kb.mapping = make(map[tcell.Key]func(*tcell.EventKey))
+		kb.mapping = make(map[keySpec]func(*tcell.EventKey))
+		kb.modeMapping = make(map[Mode]map[keySpec]func(*tcell.EventKey))
 	}
+	if len(modes) == 0 {
+		kb.mapping[spec] = fn
+		return
+	}
+	for _, m := range modes {
+		if kb.modeMapping[m] == nil {
+			kb.modeMapping[m] = make(map[keySpec]func(*tcell.EventKey))
+		}
+		kb.modeMapping[m][spec] = fn
+	}
+}
 
-	if len(keys) == 0 {
-		return kb
+// find returns the handler of the key event in the given mode.
+// The bindings of the mode take precedence over the global ones,
+// and the modifiers will be ignored if there is no exact binding.
+func (kb *keyBinder) find(mode Mode, ev *tcell.EventKey) func(*tcell.EventKey) {
+	spec := newKeySpec(ev.Key(), ev.Modifiers(), ev.Rune())
+	candidates := []keySpec{spec}
+	if spec.key == tcell.KeyRune {
+		candidates = append(candidates, keySpec{key: tcell.KeyRune, mod: spec.mod})
+	}
+	if spec.mod != tcell.ModNone {
+		for _, c := range candidates {
+			c.mod = tcell.ModNone
+			candidates = append(candidates, c)
+		}
 	}
+	for _, c := range candidates {
+		if fn := kb.modeMapping[mode][c]; fn != nil {
+			return fn
+		}
+		if fn := kb.mapping[c]; fn != nil {
+			return fn
+		}
+	}
+	return nil
+}
 
-	for _, k := range keys {
-		if _, ok := kb.mapping[k]; ok {
-			// do not try to recover this
-			panic(fmt.Sprintf("key [%v] bind twice", k))
+// Bind binds the key spec to the action in the given modes, or all modes if no
+// mode was given. The bindings of a specific mode take precedence over the
+// global ones, and binding a key twice replaces the old action.
+//
+// The spec looks like "ctrl-a", "alt-b", "shift-tab", "enter", "f1" or a single
+// char like "j", see parseKeySpec for details. "ctrl-h", "ctrl-i", "ctrl-m" and
+// "ctrl-[" are rejected, the terminals send them as backspace, tab, enter and esc.
+//
+// The action is either the name of a built-in action or a func(*MenuScreen).
+// Built-in actions are:
+//
+//	up, down, backward-char, forward-char, backward-delete-char,
+//	delete-char, beginning-of-line, end-of-line, backward-word, forward-word,
+//	kill-word, backward-kill-word, unix-word-rubout, unix-line-discard,
+//	kill-line, yank, prev-history, next-history,
+//	accept, abort, cancel, search, input, toggle-search,
+//	toggle, toggle+down, toggle+up, cycle-matcher, toggle-sort,
+//	preview-up, preview-down, toggle-section, ignore
+func (menu *MenuScreen) Bind(spec string, action any, modes ...Mode) error {
+	ks, err := parseKeySpec(spec)
+	if err != nil {
+		return err
+	}
+	var fn func(*tcell.EventKey)
+	switch act := action.(type) {
+	case string:
+		if fn = menu.actions[act]; fn == nil {
+			return fmt.Errorf("unknown action: %q", act)
 		}
-		kb.mapping[k] = fn
+	case func(*MenuScreen):
+		fn = func(*tcell.EventKey) {
+			act(menu)
+			// the handler may change anything, so redraw the whole screen
+			menu.hasFilled = false
+		}
+	default:
+		return fmt.Errorf("invalid action type: %T", action)
+	}
+	menu.keyBinder.bindSpec(fn, ks, modes...)
+	return nil
+}
+
+// Do executes the built-in action with the given name, it's useful in custom key handlers.
+func (menu *MenuScreen) Do(action string) error {
+	fn := menu.actions[action]
+	if fn == nil {
+		return fmt.Errorf("unknown action: %q", action)
 	}
+	fn(nil)
+	return nil
+}
 
-	return kb
+// NewKeyEvent creates the key event described by spec, see Bind for the syntax.
+// It's useful to simulate key presses on a tcell.SimulationScreen.
+func NewKeyEvent(spec string) (*tcell.EventKey, error) {
+	ks, err := parseKeySpec(spec)
+	if err != nil {
+		return nil, err
+	}
+	return tcell.NewEventKey(ks.key, ks.r, ks.mod), nil
+}
+
+var namedKeys = map[string]tcell.Key{
+	"enter":     tcell.KeyEnter,
+	"return":    tcell.KeyEnter,
+	"esc":       tcell.KeyEsc,
+	"tab":       tcell.KeyTab,
+	"btab":      tcell.KeyBacktab,
+	"backspace": tcell.KeyBackspace2,
+	"bspace":    tcell.KeyBackspace2,
+	"delete":    tcell.KeyDelete,
+	"del":       tcell.KeyDelete,
+	"insert":    tcell.KeyInsert,
+	"up":        tcell.KeyUp,
+	"down":      tcell.KeyDown,
+	"left":      tcell.KeyLeft,
+	"right":     tcell.KeyRight,
+	"home":      tcell.KeyHome,
+	"end":       tcell.KeyEnd,
+	"pgup":      tcell.KeyPgUp,
+	"pgdn":      tcell.KeyPgDn,
+}
+
+// parseKeySpec parses specs like "ctrl-a", "alt-b", "shift-tab", "enter", "f1" and "j".
+// The modifiers "ctrl", "alt" and "shift" can be combined, e.g. "ctrl-alt-x".
+func parseKeySpec(spec string) (keySpec, error) {
+	var mod tcell.ModMask
+	name := spec
+	for {
+		lower := strings.ToLower(name)
+		switch {
+		case strings.HasPrefix(lower, "ctrl-") && len(name) > 5:
+			mod |= tcell.ModCtrl
+			name = name[5:]
+			continue
+		case strings.HasPrefix(lower, "alt-") && len(name) > 4:
+			mod |= tcell.ModAlt
+			name = name[4:]
+			continue
+		case strings.HasPrefix(lower, "shift-") && len(name) > 6:
+			mod |= tcell.ModShift
+			name = name[6:]
+			continue
+		}
+		break
+	}
+
+	if utf8.RuneCountInString(name) == 1 {
+		r, _ := utf8.DecodeRuneInString(name)
+		if mod&tcell.ModCtrl != 0 {
+			return parseCtrlRune(r, mod)
+		}
+		return newKeySpec(tcell.KeyRune, mod, r), nil
+	}
+
+	lower := strings.ToLower(name)
+	if lower == "space" {
+		if mod&tcell.ModCtrl != 0 {
+			return newKeySpec(tcell.KeyCtrlSpace, mod, 0), nil
+		}
+		return newKeySpec(tcell.KeyRune, mod, ' '), nil
+	}
+	if lower == "tab" && mod&tcell.ModShift != 0 {
+		return newKeySpec(tcell.KeyBacktab, mod&^tcell.ModShift, 0), nil
+	}
+	if k, ok := namedKeys[lower]; ok {
+		return newKeySpec(k, mod, 0), nil
+	}
+	var n int
+	if _, err := fmt.Sscanf(lower, "f%d", &n); err == nil && n >= 1 && n <= 64 {
+		return newKeySpec(tcell.KeyF1+tcell.Key(n-1), mod, 0), nil
+	}
+
+	return keySpec{}, fmt.Errorf("invalid key spec: %q", spec)
+}
+
+// sameAsCtrl are the keys which the terminals send as the ctrl chars,
+// binding the ctrl chars would rebind these keys too.
+var sameAsCtrl = map[rune]string{
+	'h': "backspace",
+	'i': "tab",
+	'm': "enter",
+	'[': "esc",
+}
+
+func parseCtrlRune(r rune, mod tcell.ModMask) (keySpec, error) {
+	if name, ok := sameAsCtrl[unicode.ToLower(r)]; ok {
+		return keySpec{}, fmt.Errorf("invalid key spec: ctrl-%c is the same as %s, use %q instead", r, name, name)
+	}
+	switch {
+	case r >= 'a' && r <= 'z':
+		return newKeySpec(tcell.KeyCtrlA+tcell.Key(r-'a'), mod, 0), nil
+	case r >= 'A' && r <= 'Z':
+		return newKeySpec(tcell.KeyCtrlA+tcell.Key(r-'A'), mod, 0), nil
+	case r == '\\':
+		return newKeySpec(tcell.KeyCtrlBackslash, mod, 0), nil
+	case r == ']':
+		return newKeySpec(tcell.KeyCtrlRightSq, mod, 0), nil
+	case r == '^':
+		return newKeySpec(tcell.KeyCtrlCarat, mod, 0), nil
+	case r == '_':
+		return newKeySpec(tcell.KeyCtrlUnderscore, mod, 0), nil
+	}
+	return keySpec{}, fmt.Errorf("invalid key spec: ctrl-%c", r)
+}
+
+// keyName returns the spec of the key event in the format of Bind,
+// e.g. "enter", "ctrl-c", "alt-b" and "q".
+func keyName(ev *tcell.EventKey) string {
+	spec := newKeySpec(ev.Key(), ev.Modifiers(), ev.Rune())
+	key, mod := spec.key, spec.mod
+
+	var name string
+	switch {
+	case key == tcell.KeyRune && spec.r == ' ':
+		name = "space"
+	case key == tcell.KeyRune:
+		name = string(spec.r)
+	case keyNames[key] != "":
+		// enter, tab and so on are ctrl keys too, so they go first
+		name = keyNames[key]
+	case key == tcell.KeyBacktab:
+		name, mod = "tab", mod|tcell.ModShift
+	case key >= tcell.KeyCtrlA && key <= tcell.KeyCtrlZ:
+		name, mod = string(rune('a'+key-tcell.KeyCtrlA)), mod|tcell.ModCtrl
+	case ctrlKeyNames[key] != "":
+		name, mod = ctrlKeyNames[key], mod|tcell.ModCtrl
+	case key >= tcell.KeyF1 && key <= tcell.KeyF64:
+		name = fmt.Sprintf("f%d", key-tcell.KeyF1+1)
+	}
+	if name == "" {
+		return ev.Name()
+	}
+
+	if mod&tcell.ModShift != 0 {
+		name = "shift-" + name
+	}
+	if mod&tcell.ModAlt != 0 {
+		name = "alt-" + name
+	}
+	if mod&tcell.ModCtrl != 0 {
+		name = "ctrl-" + name
+	}
+	return name
+}
 
+// keyNames are the preferred names of the keys in namedKeys.
+var keyNames = map[tcell.Key]string{
+	tcell.KeyEnter:      "enter",
+	tcell.KeyEsc:        "esc",
+	tcell.KeyTab:        "tab",
+	tcell.KeyBackspace2: "backspace",
+	tcell.KeyDelete:     "delete",
+	tcell.KeyInsert:     "insert",
+	tcell.KeyUp:         "up",
+	tcell.KeyDown:       "down",
+	tcell.KeyLeft:       "left",
+	tcell.KeyRight:      "right",
+	tcell.KeyHome:       "home",
+	tcell.KeyEnd:        "end",
+	tcell.KeyPgUp:       "pgup",
+	tcell.KeyPgDn:       "pgdn",
 }
 
-func (kb *keyBinder) find(key tcell.Key) func(*tcell.EventKey) {
-	return kb.mapping[key]
+// ctrlKeyNames are the names of the ctrl keys without the "ctrl-" prefix.
+var ctrlKeyNames = map[tcell.Key]string{
+	tcell.KeyCtrlSpace:      "space",
+	tcell.KeyCtrlBackslash:  "\\",
+	tcell.KeyCtrlRightSq:    "]",
+	tcell.KeyCtrlCarat:      "^",
+	tcell.KeyCtrlUnderscore: "_",
 }
@@ -0,0 +1,121 @@
+package menuscreen
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestParseKeySpec(t *testing.T) {
+	tests := []struct {
+		spec string
+		want keySpec
+	}{
+		{"j", keySpec{key: tcell.KeyRune, r: 'j'}},
+		{"J", keySpec{key: tcell.KeyRune, r: 'J'}},
+		{"ctrl-a", keySpec{key: tcell.KeyCtrlA, mod: tcell.ModCtrl}},
+		{"CTRL-A", keySpec{key: tcell.KeyCtrlA, mod: tcell.ModCtrl}},
+		{"alt-b", keySpec{key: tcell.KeyRune, mod: tcell.ModAlt, r: 'b'}},
+		{"ctrl-alt-x", keySpec{key: tcell.KeyCtrlX, mod: tcell.ModCtrl | tcell.ModAlt}},
+		{"shift-tab", keySpec{key: tcell.KeyBacktab}},
+		{"space", keySpec{key: tcell.KeyRune, r: ' '}},
+		{"ctrl-space", keySpec{key: tcell.KeyCtrlSpace, mod: tcell.ModCtrl}},
+		{"enter", keySpec{key: tcell.KeyEnter}},
+		{"ctrl-enter", keySpec{key: tcell.KeyEnter}},
+		{"bspace", keySpec{key: tcell.KeyBackspace2}},
+		{"shift-up", keySpec{key: tcell.KeyUp, mod: tcell.ModShift}},
+		{"f1", keySpec{key: tcell.KeyF1}},
+		{"f12", keySpec{key: tcell.KeyF12}},
+		{"ctrl-]", keySpec{key: tcell.KeyCtrlRightSq, mod: tcell.ModCtrl}},
+		{"世", keySpec{key: tcell.KeyRune, r: '世'}},
+	}
+	for _, tt := range tests {
+		got, err := parseKeySpec(tt.spec)
+		if err != nil {
+			t.Errorf("parseKeySpec(%q): %v", tt.spec, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseKeySpec(%q) = %+v, want %+v", tt.spec, got, tt.want)
+		}
+	}
+}
+
+func TestParseKeySpecInvalid(t *testing.T) {
+	for _, spec := range []string{"", "foo", "ctrl-", "ctrl-1", "f0", "f65", "ctrl-foo",
+		// the terminals send them as backspace, tab, enter and esc
+		"ctrl-h", "CTRL-H", "ctrl-i", "ctrl-m", "ctrl-[", "ctrl-alt-h"} {
+		if ks, err := parseKeySpec(spec); err == nil {
+			t.Errorf("parseKeySpec(%q) = %+v, want an error", spec, ks)
+		}
+	}
+}
+
+func TestKeyBinderFind(t *testing.T) {
+	var got string
+	handler := func(name string) func(*tcell.EventKey) {
+		return func(*tcell.EventKey) { got = name }
+	}
+	var kb keyBinder
+	kb.bindSpec(handler("global-j"), newKeySpec(tcell.KeyRune, tcell.ModNone, 'j'))
+	kb.bindSpec(handler("search-j"), newKeySpec(tcell.KeyRune, tcell.ModNone, 'j'), modeS)
+	kb.bindSpec(handler("runes"), newKeySpec(tcell.KeyRune, tcell.ModNone, 0), modeI)
+	kb.bindSpec(handler("bs"), newKeySpec(tcell.KeyBackspace, tcell.ModNone, 0))
+
+	tests := []struct {
+		mode Mode
+		ev   *tcell.EventKey
+		want string
+	}{
+		{modeN, tcell.NewEventKey(tcell.KeyRune, 'j', tcell.ModNone), "global-j"},
+		{modeS, tcell.NewEventKey(tcell.KeyRune, 'j', tcell.ModNone), "search-j"},
+		{modeI, tcell.NewEventKey(tcell.KeyRune, 'k', tcell.ModNone), "runes"},
+		{modeI, tcell.NewEventKey(tcell.KeyRune, 'x', tcell.ModAlt), "runes"},
+		{modeN, tcell.NewEventKey(tcell.KeyRune, 'j', tcell.ModAlt), "global-j"},
+		{modeN, tcell.NewEventKey(tcell.KeyBackspace2, 0, tcell.ModNone), "bs"},
+		{modeN, tcell.NewEventKey(tcell.KeyRune, 'x', tcell.ModNone), ""},
+	}
+	for _, tt := range tests {
+		got = ""
+		if fn := kb.find(tt.mode, tt.ev); fn != nil {
+			fn(tt.ev)
+		}
+		if got != tt.want {
+			t.Errorf("find(%v, %s) called %q, want %q", tt.mode, tt.ev.Name(), got, tt.want)
+		}
+	}
+}
+
+func TestKeyName(t *testing.T) {
+	tests := []struct {
+		ev   *tcell.EventKey
+		want string
+	}{
+		{tcell.NewEventKey(tcell.KeyRune, 'j', tcell.ModNone), "j"},
+		{tcell.NewEventKey(tcell.KeyRune, ' ', tcell.ModNone), "space"},
+		{tcell.NewEventKey(tcell.KeyRune, 'x', tcell.ModAlt), "alt-x"},
+		{tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone), "enter"},
+		{tcell.NewEventKey(tcell.KeyTab, 0, tcell.ModNone), "tab"},
+		{tcell.NewEventKey(tcell.KeyBacktab, 0, tcell.ModNone), "shift-tab"},
+		{tcell.NewEventKey(tcell.KeyBackspace, 0, tcell.ModNone), "backspace"},
+		{tcell.NewEventKey(tcell.KeyBackspace2, 0, tcell.ModNone), "backspace"},
+		{tcell.NewEventKey(tcell.KeyCtrlA, 0, tcell.ModCtrl), "ctrl-a"},
+		// some terminals do not report the ctrl modifier of the ctrl keys
+		{tcell.NewEventKey(tcell.KeyCtrlA, 0, tcell.ModNone), "ctrl-a"},
+		{tcell.NewEventKey(tcell.KeyCtrlSpace, 0, tcell.ModCtrl), "ctrl-space"},
+		{tcell.NewEventKey(tcell.KeyCtrlRightSq, 0, tcell.ModCtrl), "ctrl-]"},
+		{tcell.NewEventKey(tcell.KeyF5, 0, tcell.ModShift), "shift-f5"},
+		{tcell.NewEventKey(tcell.KeyUp, 0, tcell.ModCtrl|tcell.ModAlt), "ctrl-alt-up"},
+	}
+	for _, tt := range tests {
+		got := keyName(tt.ev)
+		if got != tt.want {
+			t.Errorf("keyName(%s) = %q, want %q", tt.ev.Name(), got, tt.want)
+			continue
+		}
+		// the name can be used by Bind
+		if _, err := parseKeySpec(got); err != nil {
+			t.Errorf("parseKeySpec(%q): %v", got, err)
+		}
+	}
+}
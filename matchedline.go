@@ -18,22 +18,17 @@ package menuscreen
 
 type matchedLines []*matchedLine
 
-func strSliceToMatchedLines(strs []string) matchedLines {
-	lns := make(matchedLines, 0, len(strs))
-	for i, s := range strs {
-		lns = append(lns, &matchedLine{
-			idx:     i,
-			content: s,
-		})
-	}
-	return lns
-}
-
 type matchedLine struct {
 	idx     int
 	content string
 	pos     []int
 	item    any
+	// guide is the indentation guides of the line in a tree.
+	guide string
+	// open is a node in a tree whose children are shown under it.
+	open bool
+	// context is an ancestor in a tree which is shown above the matches.
+	context bool
 }
 
 func (lns matchedLines) Content() []string {
@@ -0,0 +1,272 @@
+package menuscreen
+
+import (
+	"reflect"
+	"regexp"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/sshelll/fzflib/algo"
+	"github.com/sshelll/fzflib/util"
+)
+
+// Matcher matches the query of the search mode against the lines.
+type Matcher interface {
+	// Name is shown next to the slash of the query line.
+	Name() string
+	// Match returns the results of the targets matched by the query.
+	Match(query string, targets []string) []MatchResult
+}
+
+// MatchResult is a target matched by a Matcher.
+type MatchResult struct {
+	// Index is the index of the target.
+	Index int
+	// Score is the score of the match, the higher the better.
+	Score int
+	// Pos is the positions of the matched runes, which will be highlighted.
+	Pos []int
+}
+
+// FuzzyMatcher returns the fzf-like fuzzy Matcher, it's the default one.
+func FuzzyMatcher() Matcher {
+	return &algoMatcher{name: "fuzzy", fn: algo.FuzzyMatchV2}
+}
+
+// ExactMatcher returns a Matcher which matches the lines containing the query.
+func ExactMatcher() Matcher {
+	return &algoMatcher{name: "exact", fn: algo.ExactMatchNaive}
+}
+
+// PrefixMatcher returns a Matcher which matches the lines starting with the query.
+func PrefixMatcher() Matcher {
+	return &algoMatcher{name: "prefix", fn: algo.PrefixMatch}
+}
+
+// WordMatcher returns a Matcher which matches the lines containing the query as a whole word.
+func WordMatcher() Matcher {
+	return wordMatcher{}
+}
+
+// RegexMatcher returns a Matcher which matches the lines by the query as a regular expression.
+// The query will be matched literally if it's not a valid regular expression.
+func RegexMatcher() Matcher {
+	return &regexMatcher{}
+}
+
+// defaultMatchers returns the matchers cycled by 'ctrl-r' in the search mode.
+func defaultMatchers() []Matcher {
+	return []Matcher{FuzzyMatcher(), ExactMatcher(), PrefixMatcher(), WordMatcher(), RegexMatcher()}
+}
+
+// SetMatcher sets the Matcher of the search mode, it'll be added to the matchers
+// cycled by 'ctrl-r' if it's not in there. A matcher whose type is not comparable,
+// e.g. a struct with a slice field, is always added.
+func (menu *MenuScreen) SetMatcher(m Matcher) *MenuScreen {
+	for i, c := range menu.matchers {
+		if sameMatcher(c, m) {
+			menu.matcherIdx = i
+			menu.matcherGen++
+			return menu
+		}
+	}
+	menu.matchers = append([]Matcher{m}, menu.matchers...)
+	menu.matcherIdx = 0
+	menu.matcherGen++
+	return menu
+}
+
+// SetMatchers sets the matchers cycled by 'ctrl-r' in the search mode, the first one will be used.
+func (menu *MenuScreen) SetMatchers(ms ...Matcher) *MenuScreen {
+	if len(ms) == 0 {
+		ms = defaultMatchers()
+	}
+	menu.matchers = ms
+	menu.matcherIdx = 0
+	menu.matcherGen++
+	return menu
+}
+
+// sameMatcher reports whether a and b are the same matcher, without panicking
+// on the matchers which are not comparable.
+func sameMatcher(a, b Matcher) bool {
+	t := reflect.TypeOf(a)
+	return t == reflect.TypeOf(b) && t != nil && t.Comparable() && a == b
+}
+
+func (menu *MenuScreen) matcher() Matcher {
+	return menu.matchers[menu.matcherIdx]
+}
+
+// keyCYCLEMATCHER switches to the next matcher and re-runs the query.
+func (menu *MenuScreen) keyCYCLEMATCHER(*tcell.EventKey) {
+	menu.matcherIdx = (menu.matcherIdx + 1) % len(menu.matchers)
+	menu.matcherGen++
+	if menu.mode == modeS {
+		menu.cursorY, menu.lastCursorY = 0, 0
+		menu.calMatchedLines()
+	}
+}
+
+// hasUpper tells whether the query should be matched case-sensitively, just like the smart case of fzf.
+func hasUpper(query string) bool {
+	for _, c := range query {
+		if unicode.IsUpper(c) {
+			return true
+		}
+	}
+	return false
+}
+
+type algoFn func(caseSensitive bool, normalize bool, forward bool, text *util.Chars, pattern []rune, withPos bool, slab *util.Slab) (algo.Result, *[]int)
+
+// algoMatcher matches by the algorithms of fzf.
+type algoMatcher struct {
+	name string
+	fn   algoFn
+	// slab is reused by the algorithms to avoid allocations,
+	// so the matcher should not be used concurrently.
+	slab *util.Slab
+}
+
+func (m *algoMatcher) Name() string {
+	return m.name
+}
+
+func (m *algoMatcher) Match(query string, targets []string) []MatchResult {
+	chars := make([]util.Chars, 0, len(targets))
+	for _, t := range targets {
+		chars = append(chars, util.ToChars([]byte(t)))
+	}
+	return m.matchIndex(query, chars, nil)
+}
+
+// matchIndex matches the candidates in chars, or all of them if cands is nil.
+func (m *algoMatcher) matchIndex(query string, chars []util.Chars, cands []int) []MatchResult {
+	pattern := []rune(query)
+	caseSensitive := hasUpper(query)
+	if cands == nil {
+		cands = rangeIdxs(0, len(chars))
+	}
+	if m.slab == nil {
+		// the same size as fzf
+		m.slab = util.MakeSlab(100*1024, 2048)
+	}
+	results := make([]MatchResult, 0, len(cands))
+	for _, i := range cands {
+		r, pos := m.fn(caseSensitive, false, true, &chars[i], pattern, true, m.slab)
+		if r.Start < 0 || r.Score == 0 {
+			continue
+		}
+		res := MatchResult{Index: i, Score: r.Score}
+		if pos != nil {
+			res.Pos = *pos
+		} else {
+			res.Pos = rangePos(r.Start, r.End)
+		}
+		results = append(results, res)
+	}
+	return results
+}
+
+type wordMatcher struct{}
+
+func (wordMatcher) Name() string {
+	return "word"
+}
+
+func (wordMatcher) Match(query string, targets []string) []MatchResult {
+	caseSensitive := hasUpper(query)
+	pattern := []rune(query)
+	results := make([]MatchResult, 0, len(targets))
+	for i, t := range targets {
+		runes := []rune(t)
+		if !caseSensitive {
+			// NOTE: ToLower may change the count of runes in rare cases,
+			// so only lower the runes one by one to keep the positions.
+			for j, c := range runes {
+				runes[j] = unicode.ToLower(c)
+			}
+		}
+		if start := indexWord(runes, pattern); start >= 0 {
+			results = append(results, MatchResult{
+				Index: i,
+				// the earlier the better
+				Score: -start,
+				Pos:   rangePos(start, start+len(pattern)),
+			})
+		}
+	}
+	return results
+}
+
+// indexWord returns the index of the first occurrence of word in runes, which is not
+// surrounded by letters or digits, or -1 if there's no such occurrence.
+func indexWord(runes, word []rune) int {
+	if len(word) == 0 {
+		return -1
+	}
+	isWordRune := func(i int) bool {
+		return i >= 0 && i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]))
+	}
+outer:
+	for i := 0; i+len(word) <= len(runes); i++ {
+		for j, c := range word {
+			if runes[i+j] != c {
+				continue outer
+			}
+		}
+		if !isWordRune(i-1) && !isWordRune(i+len(word)) {
+			return i
+		}
+	}
+	return -1
+}
+
+// regexMatcher caches the last compiled query.
+type regexMatcher struct {
+	query string
+	re    *regexp.Regexp
+}
+
+func (m *regexMatcher) Name() string {
+	return "regex"
+}
+
+func (m *regexMatcher) Match(query string, targets []string) []MatchResult {
+	if m.re == nil || m.query != query {
+		expr := query
+		if _, err := regexp.Compile(expr); err != nil {
+			expr = regexp.QuoteMeta(query)
+		}
+		if !hasUpper(query) {
+			expr = "(?i)" + expr
+		}
+		m.query, m.re = query, regexp.MustCompile(expr)
+	}
+	results := make([]MatchResult, 0, len(targets))
+	for i, t := range targets {
+		loc := m.re.FindStringIndex(t)
+		if loc == nil {
+			continue
+		}
+		start := utf8.RuneCountInString(t[:loc[0]])
+		end := start + utf8.RuneCountInString(t[loc[0]:loc[1]])
+		results = append(results, MatchResult{
+			Index: i,
+			// the earlier and the shorter the better
+			Score: -start - (end - start),
+			Pos:   rangePos(start, end),
+		})
+	}
+	return results
+}
+
+func rangePos(start, end int) []int {
+	pos := make([]int, 0, end-start)
+	for i := start; i < end; i++ {
+		pos = append(pos, i)
+	}
+	return pos
+}
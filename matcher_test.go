@@ -0,0 +1,66 @@
+package menuscreen
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestMatchers(t *testing.T) {
+	targets := []string{"foo bar", "foobar", "Bar", "barfoo", "a.b"}
+	tests := []struct {
+		m     Matcher
+		query string
+		want  []int
+	}{
+		{FuzzyMatcher(), "bar", []int{0, 1, 2, 3}},
+		{FuzzyMatcher(), "Bar", []int{2}},
+		{FuzzyMatcher(), "fb", []int{0, 1}},
+		{ExactMatcher(), "bar", []int{0, 1, 2, 3}},
+		{ExactMatcher(), "fb", nil},
+		{PrefixMatcher(), "bar", []int{2, 3}},
+		{WordMatcher(), "bar", []int{0, 2}},
+		{WordMatcher(), "foo", []int{0}},
+		{RegexMatcher(), "^foo", []int{0, 1}},
+		{RegexMatcher(), "a.b", []int{4}},
+		{RegexMatcher(), "o{2}b", []int{1}},
+		// an invalid regular expression is matched literally
+		{RegexMatcher(), "b[", nil},
+	}
+	for _, tt := range tests {
+		var got []int
+		for _, r := range tt.m.Match(tt.query, targets) {
+			got = append(got, r.Index)
+		}
+		slices.Sort(got)
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s matcher: Match(%q) = %v, want %v", tt.m.Name(), tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestMatcherPositions(t *testing.T) {
+	tests := []struct {
+		m      Matcher
+		query  string
+		target string
+		want   []int
+	}{
+		{FuzzyMatcher(), "fb", "foo bar", []int{0, 4}},
+		{ExactMatcher(), "bar", "foobar", []int{3, 4, 5}},
+		{PrefixMatcher(), "foo", "foobar", []int{0, 1, 2}},
+		{WordMatcher(), "bar", "foo bar", []int{4, 5, 6}},
+		{RegexMatcher(), "o+b", "foobar", []int{1, 2, 3}},
+	}
+	for _, tt := range tests {
+		results := tt.m.Match(tt.query, []string{tt.target})
+		if len(results) != 1 {
+			t.Errorf("%s matcher: Match(%q, %q) = %+v", tt.m.Name(), tt.query, tt.target, results)
+			continue
+		}
+		got := slices.Clone(results[0].Pos)
+		slices.Sort(got)
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s matcher: positions of %q in %q = %v, want %v", tt.m.Name(), tt.query, tt.target, got, tt.want)
+		}
+	}
+}
@@ -17,12 +17,34 @@
 package menuscreen
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"runtime/debug"
+	"time"
 
 	"github.com/gdamore/tcell/v2"
-	"github.com/sshelll/fzflib"
 )
 
+// ErrTimedOut is returned by StartContext when the deadline of the context exceeded.
+var ErrTimedOut = errors.New("menuscreen: timed out")
+
+// contextError converts the error of a context to the error of StartContext.
+func contextError(err error) error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("%w: %w", ErrTimedOut, err)
+	}
+	return err
+}
+
+// panicError converts a recovered panic to an error with the stack.
+func panicError(r any) error {
+	if err, ok := r.(error); ok {
+		return fmt.Errorf("menuscreen: panic: %w\n%s", err, debug.Stack())
+	}
+	return fmt.Errorf("menuscreen: panic: %v\n%s", r, debug.Stack())
+}
+
 // MenuScreen is a visible selector for input.
 //
 // Terminal will show the content below in the normal mode:
@@ -51,20 +73,55 @@ import (
 type MenuScreen struct {
 	screen         tcell.Screen
 	keyBinder      *keyBinder
+	actions        map[string]func(*tcell.EventKey)
 	shutdownCtrl   chan struct{}
-	mode           screenMode
+	mode           Mode
 	cursorY        int
 	lastCursorY    int
+	offset         int
 	query          []rune
 	input          []rune
 	inputCursorPos int
+	killed         []rune
+	pasting        bool
+	pasted         []rune
 	title          string
 	lines          []string
 	items          []*MenuItem
+	table          *table
+	targets        []string
 	matchedLns     matchedLines
+	multiSelect    bool
+	marked         map[int]struct{}
+	collapsed      map[int]bool
+	tree           *tree
+	heads          []int
+	normalLns      matchedLines
+	normalCount    int
+	preview        *preview
+	streams        []func()
+	streaming      int
+	running        bool
+	mouse          mouseState
+	theme          Theme
+	themed         bool
 	confirmed      bool
 	finished       bool
-	fuzzyFinder    *fzflib.Fzf
+	matchers       []Matcher
+	matcherIdx     int
+	matcherGen     int
+	plainSearch    bool
+	index          *searchIndex
+	sortOrder      SortOrder
+	sortToggled    bool
+	history        *history
+	historySize    int
+	validator      func(string) error
+	inputErr       error
+	err            error
+	endKey         *tcell.EventKey
+	bars           [barCount]bar
+	message        string
 	hasFilled      bool
 }
 
@@ -75,6 +132,16 @@ func NewMenuScreen() (menuScreen *MenuScreen, err error) {
 		return
 	}
 
+	return NewMenuScreenWithScreen(screen)
+
+}
+
+// NewMenuScreenWithScreen creates a MenuScreen on the given screen, which
+// will be initialized here, so do not call screen.Init before.
+// It's useful to run a MenuScreen on a tcell.SimulationScreen in tests,
+// see the menuscreentest package.
+func NewMenuScreenWithScreen(screen tcell.Screen) (menuScreen *MenuScreen, err error) {
+
 	defer func() {
 		if r := recover(); r != nil {
 			screen.Fini()
@@ -85,20 +152,24 @@ func NewMenuScreen() (menuScreen *MenuScreen, err error) {
 		return
 	}
 
-	screen.SetStyle(defaultContentStyle)
+	screen.SetStyle(defaultTheme.Content)
 	screen.EnablePaste()
 	screen.DisableMouse()
 	screen.Clear()
 
 	menu := &MenuScreen{
-		screen:      screen,
-		lines:       make([]string, 0, 16),
-		matchedLns:  make([]*matchedLine, 0, 16),
-		mode:        modeN,
-		cursorY:     0,
-		query:       nil,
-		title:       "Menu",
-		fuzzyFinder: fzflib.New().Normalize(false).Forward(true),
+		screen:     screen,
+		lines:      make([]string, 0, 16),
+		matchedLns: make([]*matchedLine, 0, 16),
+		marked:     make(map[int]struct{}),
+		collapsed:  make(map[int]bool),
+		mode:       modeN,
+		cursorY:    0,
+		query:      nil,
+		title:      "Menu",
+		theme:      defaultTheme,
+		matchers:   defaultMatchers(),
+		bars:       defaultBars(),
 	}
 
 	menu.initKeyBinder()
@@ -106,40 +177,136 @@ func NewMenuScreen() (menuScreen *MenuScreen, err error) {
 	return menu, nil
 }
 
+// Start runs the menu until it was confirmed or aborted, the errors of
+// StartContext are printed to stderr.
 func (menu *MenuScreen) Start() *MenuScreen {
+	if err := menu.StartContext(context.Background()); err != nil {
+		println(err.Error())
+	}
+	return menu
+}
+
+// StartContext runs the menu until it was confirmed or aborted, or the ctx
+// was done. The terminal is always restored before it returns.
+//
+// When the ctx was canceled, ctx.Err() is returned; when the deadline of the ctx
+// exceeded, the returned error is ErrTimedOut, which wraps context.DeadlineExceeded.
+// The panics inside the menu are recovered and returned as errors too.
+func (menu *MenuScreen) StartContext(ctx context.Context) (err error) {
 
 	defer func() {
 		if r := recover(); r != nil {
-			println(r)
-			debug.PrintStack()
+			err = panicError(r)
+		}
+		if err != nil {
+			menu.err = err
+		}
+		// stop the goroutines of the streams and the ctx
+		if menu.shutdownCtrl != nil {
+			menu.shutdown()
 		}
 		menu.Fini()
 	}()
 
+	if err = ctx.Err(); err != nil {
+		return contextError(err)
+	}
+
 	screen := menu.screen
+	if !menu.themed {
+		// the deprecated package-level style setters may be called after the menu was created
+		menu.theme = defaultTheme
+		screen.SetStyle(defaultTheme.Content)
+	}
 	menu.shutdownCtrl = make(chan struct{})
+	menu.running = true
+	menu.err = nil
+	menu.startStreams()
+	go menu.watchContext(ctx)
 
 	for {
 
 		if menu.isShutdown() {
-			return menu
+			return menu.err
 		}
 
-		menu.refreshScreen()
+		// the screen is refreshed after the whole paste
+		if !menu.pasting {
+			menu.refreshScreen()
+		}
+
+		event := screen.PollEvent()
+		// the key is kept only if it was the last event
+		menu.endKey, _ = event.(*tcell.EventKey)
 
-		switch event := screen.PollEvent().(type) {
+		switch event := event.(type) {
 		case *tcell.EventResize:
+			menu.hasFilled = false
 			screen.Sync()
+		case *tcell.EventPaste:
+			menu.handlePaste(event)
 		case *tcell.EventKey:
-			if fn := menu.keyBinder.find(event.Key()); fn != nil {
+			// the message is shown until the next key
+			if menu.message != "" {
+				menu.message = ""
+				menu.hasFilled = false
+			}
+			if menu.pasting {
+				menu.collectPaste(event)
+			} else if fn := menu.keyBinder.find(menu.mode, event); fn != nil {
 				fn(event)
 			}
+		case *tcell.EventMouse:
+			menu.handleMouse(event)
+		case *tcell.EventInterrupt:
+			if fn, ok := event.Data().(func(*MenuScreen)); ok {
+				fn(menu)
+				// fn may change anything, so redraw the whole screen
+				menu.hasFilled = false
+			}
 		}
 
 	}
 
 }
 
+// Post runs fn on the goroutine of the event loop and then redraws the screen,
+// it's the only safe way to touch a running MenuScreen from other goroutines.
+// An error is returned if the event queue is full.
+func (menu *MenuScreen) Post(fn func(*MenuScreen)) error {
+	return menu.screen.PostEvent(tcell.NewEventInterrupt(fn))
+}
+
+// post is like Post but retries until the event was queued or the menu was shut down,
+// it must not be called on the goroutine of the event loop.
+func (menu *MenuScreen) post(fn func(*MenuScreen)) {
+	for menu.Post(fn) != nil {
+		if menu.isShutdown() {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// watchContext shuts down the menu once the ctx is done.
+func (menu *MenuScreen) watchContext(ctx context.Context) {
+	select {
+	case <-ctx.Done():
+		menu.post(func(menu *MenuScreen) {
+			menu.fail(contextError(ctx.Err()))
+		})
+	case <-menu.shutdownCtrl:
+	}
+}
+
+// fail shuts down the menu with the err, which is returned by StartContext.
+func (menu *MenuScreen) fail(err error) {
+	if menu.err == nil {
+		menu.err = err
+	}
+	menu.shutdown()
+}
+
 func (menu *MenuScreen) Fini() {
 	if !menu.finished {
 		menu.screen.Fini()
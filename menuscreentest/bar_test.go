@@ -0,0 +1,72 @@
+package menuscreentest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sshelll/menuscreen"
+)
+
+func TestBars(t *testing.T) {
+	h := newHarness(t, 60, 10)
+	h.Menu.SetLines("foo", "bar", "baz")
+	if err := h.Menu.SetHeader("head {{.Mode}}"); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Menu.SetFooter("foot {{.Cursor}}"); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Menu.SetKeyHints(menuscreen.DefaultKeyHints); err != nil {
+		t.Fatal(err)
+	}
+	start(t, h)
+	typeKeys(t, h, "j")
+
+	lines := h.Lines()
+	want := []string{"head normal", "3/3", menuscreen.DefaultKeyHints, "foot 2"}
+	got := []string{lines[1], lines[5], lines[6], lines[7]}
+	for i := range want {
+		if strings.TrimSpace(got[i]) != want[i] {
+			t.Errorf("unexpected bars %q, want %q", got, want)
+			break
+		}
+	}
+
+	typeKeys(t, h, "/ba")
+	lines = h.Lines()
+	if !strings.Contains(lines[2], "head search") || !strings.Contains(strings.Join(lines, "\n"), "2/3") {
+		t.Errorf("unexpected bars in the search mode:\n%s", strings.Join(lines, "\n"))
+	}
+	finish(t, h, "<esc><esc>")
+}
+
+func TestStatusLine(t *testing.T) {
+	h := newHarness(t, 60, 10)
+	h.Menu.SetLines("foo", "bar")
+	if err := h.Menu.SetStatusLine("{{.Matcher}} {{.Query}}"); err != nil {
+		t.Fatal(err)
+	}
+	h.Menu.SetBarPosition(menuscreen.StatusBar, menuscreen.BarTop)
+	start(t, h)
+	typeKeys(t, h, "/fo")
+	if got := strings.TrimSpace(h.Lines()[2]); got != "fuzzy fo" {
+		t.Errorf("unexpected status line %q", got)
+	}
+	finish(t, h, "<esc><esc>")
+}
+
+func TestInvalidBar(t *testing.T) {
+	h := newHarness(t, 60, 10)
+	if err := h.Menu.SetHeader("{{.Matched"); err == nil {
+		t.Error("no error for an invalid template")
+	}
+	if err := h.Menu.SetFooter("{{.Unknown}}"); err != nil {
+		t.Fatal(err)
+	}
+	h.Menu.SetLines("foo")
+	start(t, h)
+	if text := strings.Join(h.Lines(), "\n"); !strings.Contains(text, "Unknown") {
+		t.Errorf("the error of the footer is not shown:\n%s", text)
+	}
+	finish(t, h, "<esc>")
+}
@@ -0,0 +1,16 @@
+package menuscreentest
+
+import "testing"
+
+func TestBindRejectsCtrlH(t *testing.T) {
+	h := newHarness(t, 40, 10)
+	h.Menu.SetLines("foo", "bar")
+	if err := h.Menu.Bind("ctrl-h", "up"); err == nil {
+		t.Fatal("Bind(ctrl-h) should fail")
+	}
+	start(t, h)
+	typeKeys(t, h, "/barx<backspace>")
+	if r := finish(t, h, "<enter>"); r.Index != 1 || r.Query != "bar" {
+		t.Fatalf("unexpected result: %+v", r)
+	}
+}
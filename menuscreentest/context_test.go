@@ -0,0 +1,73 @@
+package menuscreentest
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sshelll/menuscreen"
+)
+
+func TestStartContextTimeout(t *testing.T) {
+	h := newHarness(t, 40, 10)
+	h.Menu.SetLines("foo")
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := h.StartContext(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.WaitDone(); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Err(); !errors.Is(err, menuscreen.ErrTimedOut) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Err = %v, want ErrTimedOut", err)
+	}
+	if _, _, ok := h.Menu.ChosenLine(); ok {
+		t.Fatal("the timed out menu should not choose a line")
+	}
+}
+
+func TestStartContextCancel(t *testing.T) {
+	h := newHarness(t, 40, 10)
+	h.Menu.SetLines("foo")
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := h.StartContext(ctx); err != nil {
+		t.Fatal(err)
+	}
+	typeKeys(t, h, "/fo")
+	cancel()
+	if err := h.WaitDone(); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Err(); err != context.Canceled {
+		t.Fatalf("Err = %v, want context.Canceled", err)
+	}
+}
+
+func TestStartContextDone(t *testing.T) {
+	h := newHarness(t, 40, 10)
+	h.Menu.SetLines("foo")
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := h.Menu.StartContext(ctx); err != context.Canceled {
+		t.Fatalf("StartContext = %v, want context.Canceled", err)
+	}
+}
+
+func TestStartContextPanic(t *testing.T) {
+	h := newHarness(t, 40, 10)
+	h.Menu.SetLines("foo")
+	if err := h.Menu.Bind("x", func(*menuscreen.MenuScreen) { panic("boom") }); err != nil {
+		t.Fatal(err)
+	}
+	start(t, h)
+	h.Type("x")
+	if err := h.WaitDone(); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Err(); err == nil || !strings.Contains(err.Error(), "panic: boom") {
+		t.Fatalf("Err = %v, want the panic", err)
+	}
+}
@@ -0,0 +1,60 @@
+package menuscreentest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sshelll/menuscreen"
+)
+
+func newDisabledHarness(t *testing.T) *Harness {
+	t.Helper()
+	h := newHarness(t, 40, 10)
+	h.Menu.AppendItems(
+		&menuscreen.MenuItem{Content: "foo", Disabled: true, DisabledReason: "no access"},
+		&menuscreen.MenuItem{Content: "bar"},
+	)
+	return h
+}
+
+func TestDisabledItem(t *testing.T) {
+	h := newDisabledHarness(t)
+	start(t, h)
+	typeKeys(t, h, "<enter>")
+	if text := strings.Join(h.Lines(), "\n"); !strings.Contains(text, "no access") {
+		t.Fatalf("the reason is not shown:\n%s", text)
+	}
+	// the reason is shown until the next key
+	typeKeys(t, h, "j")
+	if text := strings.Join(h.Lines(), "\n"); strings.Contains(text, "no access") {
+		t.Fatalf("the reason is still shown:\n%s", text)
+	}
+	if r := finish(t, h, "<enter>"); r.Outcome != menuscreen.OutcomeAccepted || r.Index != 1 {
+		t.Fatalf("unexpected result: %+v", r)
+	}
+}
+
+func TestDisabledItemIsNotMarked(t *testing.T) {
+	h := newDisabledHarness(t)
+	h.Menu.AppendItems(&menuscreen.MenuItem{Content: "baz"})
+	h.Menu.SetMultiSelect(true)
+	start(t, h)
+	typeKeys(t, h, "<tab>j<tab>")
+	finish(t, h, "<enter>")
+	if idxs, _, ok := h.Menu.ChosenLines(); !ok || len(idxs) != 1 || idxs[0] != 1 {
+		t.Fatalf("unexpected chosen lines: %v", idxs)
+	}
+}
+
+func TestDisabledReasonWithoutStatusLine(t *testing.T) {
+	h := newDisabledHarness(t)
+	if err := h.Menu.SetStatusLine(""); err != nil {
+		t.Fatal(err)
+	}
+	start(t, h)
+	typeKeys(t, h, "<enter>")
+	if text := strings.Join(h.Lines(), "\n"); !strings.Contains(text, "no access") {
+		t.Fatalf("the reason is not shown:\n%s", text)
+	}
+	finish(t, h, "<esc>")
+}
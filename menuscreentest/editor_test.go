@@ -0,0 +1,53 @@
+package menuscreentest
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEditingKeys(t *testing.T) {
+	tests := []struct {
+		keys string
+		want string
+	}{
+		{"hello world<ctrl-a>X", "Xhello world"},
+		{"hello<home>X<end>!", "Xhello!"},
+		{"hello<ctrl-a><ctrl-e>!", "hello!"},
+		{"abc<left><left><ctrl-d>", "ac"},
+		{"abc<left><delete>", "ab"},
+		{"abc<ctrl-b><ctrl-b><ctrl-f>X", "abXc"},
+		{"foo bar<alt-b>X", "foo Xbar"},
+		{"foo bar<ctrl-a><alt-f>X", "fooX bar"},
+		{"foo bar<ctrl-a><alt-d>", " bar"},
+		{"foo.bar<alt-bspace>", "foo."},
+		{"foo.bar baz<ctrl-w>", "foo.bar "},
+		{"foo bar<alt-b><ctrl-u>", "bar"},
+		{"foo bar<alt-b><ctrl-k>", "foo "},
+		{"foo bar<ctrl-w><ctrl-a><ctrl-y>", "barfoo "},
+		{"a世界<left>b", "a世b界"},
+	}
+	for _, tt := range tests {
+		h := newHarness(t, 40, 10)
+		h.Menu.SetLines("foo")
+		start(t, h)
+		finish(t, h, ":"+tt.keys+"<enter>")
+		if _, ln, ok := h.Menu.ChosenLine(); !ok || ln != tt.want {
+			t.Errorf("%q gives %q, want %q", tt.keys, ln, tt.want)
+		}
+	}
+}
+
+func TestEditingQuery(t *testing.T) {
+	h := newHarness(t, 40, 10)
+	h.Menu.SetLines("foo", "bar")
+	start(t, h)
+
+	typeKeys(t, h, "/xbar<ctrl-a><ctrl-d>")
+	if text := h.Text(); !strings.Contains(text, "/bar") || !strings.Contains(text, "▸ bar") {
+		t.Fatalf("the lines should be matched again after editing:\n%s", text)
+	}
+	if x, _, visible := h.Cursor(); !visible || h.Lines()[1][x:] != "bar" {
+		t.Fatalf("the cursor should be at the start of the query, got %d in %q", x, h.Lines()[1])
+	}
+	finish(t, h, "<esc><esc>")
+}
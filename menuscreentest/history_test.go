@@ -0,0 +1,59 @@
+package menuscreentest
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHistoryRecall(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history")
+	for _, q := range []string{"foo", "bar"} {
+		h := newHarness(t, 40, 10)
+		h.Menu.SetLines("foo", "bar").SetHistoryFile(path)
+		start(t, h)
+		finish(t, h, "/"+q+"<enter>")
+	}
+
+	h := newHarness(t, 40, 10)
+	h.Menu.SetLines("foo", "bar").SetHistoryFile(path)
+	start(t, h)
+	typeKeys(t, h, "/ba")
+	steps := []struct {
+		keys  string
+		query string
+	}{
+		{"<ctrl-p>", "/bar"},
+		{"<ctrl-p>", "/foo"},
+		// the oldest entry stays
+		{"<ctrl-p>", "/foo"},
+		{"<ctrl-n>", "/bar"},
+		// the line before browsing is back at the end
+		{"<ctrl-n>", "/ba"},
+	}
+	for _, s := range steps {
+		typeKeys(t, h, s.keys)
+		if ln := h.Lines()[1]; !strings.HasSuffix(ln, s.query) {
+			t.Fatalf("after %s the query line is %q, want %q", s.keys, ln, s.query)
+		}
+	}
+	finish(t, h, "<esc><esc>")
+}
+
+func TestHistorySizeBeforeFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history")
+	for _, q := range []string{"foo", "bar"} {
+		h := newHarness(t, 40, 10)
+		h.Menu.SetLines("foo", "bar").SetHistorySize(1).SetHistoryFile(path)
+		start(t, h)
+		finish(t, h, "/"+q+"<enter>")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "bar\n" {
+		t.Fatalf("history = %q, want only the last query", data)
+	}
+}
@@ -0,0 +1,81 @@
+package menuscreentest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sshelll/menuscreen"
+)
+
+func TestCycleMatchers(t *testing.T) {
+	h := newHarness(t, 40, 10)
+	h.Menu.SetLines("foo bar", "fob").SetMatchers(menuscreen.FuzzyMatcher(), menuscreen.ExactMatcher())
+	start(t, h)
+
+	typeKeys(t, h, "/fb")
+	if text := h.Text(); !strings.Contains(text, "[fuzzy]") || !strings.Contains(text, "fob") {
+		t.Fatalf("unexpected screen:\n%s", text)
+	}
+	typeKeys(t, h, "<ctrl-r>")
+	if text := h.Text(); !strings.Contains(text, "[exact]") || strings.Contains(text, "fob") {
+		t.Fatalf("ctrl-r should switch to the exact matcher:\n%s", text)
+	}
+	typeKeys(t, h, "<ctrl-r>")
+	if text := h.Text(); !strings.Contains(text, "[fuzzy]") {
+		t.Fatalf("ctrl-r should cycle back to the fuzzy matcher:\n%s", text)
+	}
+	finish(t, h, "<esc><esc>")
+}
+
+func TestSetMatcher(t *testing.T) {
+	h := newHarness(t, 40, 10)
+	h.Menu.SetLines("foobar", "barfoo").SetMatcher(menuscreen.PrefixMatcher())
+	start(t, h)
+
+	typeKeys(t, h, "/bar")
+	if text := h.Text(); !strings.Contains(text, "[prefix]") || strings.Contains(text, "foobar") {
+		t.Fatalf("unexpected screen:\n%s", text)
+	}
+	finish(t, h, "<enter>")
+	if idx, _, ok := h.Menu.ChosenLine(); !ok || idx != 1 {
+		t.Fatalf("ChosenLine() = %d, %v", idx, ok)
+	}
+}
+
+// listMatcher is not comparable because of the slice field.
+type listMatcher struct {
+	words []string
+}
+
+func (listMatcher) Name() string {
+	return "list"
+}
+
+func (listMatcher) Match(query string, targets []string) []menuscreen.MatchResult {
+	var results []menuscreen.MatchResult
+	for i, t := range targets {
+		if strings.Contains(t, query) {
+			results = append(results, menuscreen.MatchResult{Index: i})
+		}
+	}
+	return results
+}
+
+func TestUncomparableMatcher(t *testing.T) {
+	h := newHarness(t, 40, 10)
+	h.Menu.SetLines("abc", "abd", "xyz")
+	h.Menu.SetMatcher(listMatcher{words: []string{"a"}})
+	h.Menu.SetMatcher(listMatcher{words: []string{"b"}})
+	start(t, h)
+
+	typeKeys(t, h, "/ab")
+	if text := h.Text(); !strings.Contains(text, "[list]") || strings.Contains(text, "xyz") {
+		t.Fatalf("unexpected screen:\n%s", text)
+	}
+	// the results of "ab" are cached for "abc"
+	typeKeys(t, h, "c")
+	finish(t, h, "<enter>")
+	if idx, _, ok := h.Menu.ChosenLine(); !ok || idx != 0 {
+		t.Fatalf("ChosenLine() = %d, %v", idx, ok)
+	}
+}
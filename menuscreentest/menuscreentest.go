@@ -0,0 +1,241 @@
+// Package menuscreentest helps to test a MenuScreen without a TTY.
+//
+// A Harness runs the MenuScreen on a tcell.SimulationScreen, feeds key
+// sequences to it and returns the rendered text:
+//
+//	h, _ := menuscreentest.New(80, 25)
+//	h.Menu.SetLines("foo", "bar")
+//	h.Start()
+//	h.Type("/ba")
+//	lines := h.Lines()
+//	h.Type("<enter>")
+//	h.WaitDone()
+//	idx, ln, ok := h.Menu.ChosenLine()
+package menuscreentest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/mattn/go-runewidth"
+	"github.com/sshelll/menuscreen"
+)
+
+// DefaultTimeout is the default timeout of Harness.
+var DefaultTimeout = 5 * time.Second
+
+// ErrTimeout is returned when the event loop does not respond in time.
+var ErrTimeout = errors.New("menuscreentest: timed out waiting for the event loop")
+
+// ErrFinished is returned when the MenuScreen has already finished.
+var ErrFinished = errors.New("menuscreentest: menu screen has finished")
+
+// Harness runs a MenuScreen on a tcell.SimulationScreen.
+type Harness struct {
+	Menu    *menuscreen.MenuScreen
+	Screen  tcell.SimulationScreen
+	Timeout time.Duration
+
+	done chan struct{}
+	err  error
+}
+
+// New creates a Harness with a simulation screen in the given size.
+func New(width, height int) (*Harness, error) {
+	screen := tcell.NewSimulationScreen("UTF-8")
+	menu, err := menuscreen.NewMenuScreenWithScreen(screen)
+	if err != nil {
+		return nil, err
+	}
+	screen.SetSize(width, height)
+	return &Harness{
+		Menu:    menu,
+		Screen:  screen,
+		Timeout: DefaultTimeout,
+		done:    make(chan struct{}),
+	}, nil
+}
+
+// Start runs the event loop of the MenuScreen in background
+// and waits until the first frame was drawn.
+func (h *Harness) Start() error {
+	return h.StartContext(context.Background())
+}
+
+// StartContext is like Start but runs the MenuScreen by StartContext,
+// the error returned by it can be got by Err after the event loop exits.
+func (h *Harness) StartContext(ctx context.Context) error {
+	go func() {
+		defer close(h.done)
+		h.err = h.Menu.StartContext(ctx)
+	}()
+	return h.Wait()
+}
+
+// Type feeds the key sequence to the MenuScreen and waits for the redraw.
+// Plain chars are typed as runes, and keys in angle brackets are parsed by
+// menuscreen.NewKeyEvent, e.g. "/abc<enter>", "<ctrl-a>" or "<down><down>".
+// Use "<lt>" to type a literal '<'.
+//
+// It's fine for the sequence to finish the menu, e.g. "<enter>", nil is returned
+// then unless the MenuScreen returned an error, which is returned as well as by Err.
+func (h *Harness) Type(keys string) error {
+	events, err := parseKeys(keys)
+	if err != nil {
+		return err
+	}
+	for _, ev := range events {
+		// PostEventWait would block forever once the menu stops reading
+		for {
+			select {
+			case <-h.done:
+				if h.err != nil {
+					return h.err
+				}
+				return ErrFinished
+			default:
+			}
+			if h.Screen.PostEvent(ev) == nil {
+				break
+			}
+			select {
+			case <-h.done:
+			case <-time.After(time.Millisecond):
+			}
+		}
+	}
+	if err = h.Wait(); err == ErrFinished {
+		// the sequence may finish the menu on purpose, e.g. "<enter>"
+		return h.Err()
+	}
+	return err
+}
+
+// Wait waits until all the pending events were handled and the screen was redrawn.
+func (h *Harness) Wait() error {
+	// the loop redraws the screen before polling the next event,
+	// so the 2nd barrier is handled only after the redraw of the 1st one.
+	for i := 0; i < 2; i++ {
+		if err := h.barrier(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (h *Harness) barrier() error {
+	reached := make(chan struct{})
+	deadline := time.After(h.Timeout)
+	for h.Menu.Post(func(*menuscreen.MenuScreen) { close(reached) }) != nil {
+		select {
+		case <-h.done:
+			return ErrFinished
+		case <-deadline:
+			return ErrTimeout
+		case <-time.After(time.Millisecond):
+		}
+	}
+	select {
+	case <-reached:
+		return nil
+	case <-h.done:
+		return ErrFinished
+	case <-deadline:
+		return ErrTimeout
+	}
+}
+
+// Done returns a channel which is closed when the event loop exits.
+func (h *Harness) Done() <-chan struct{} {
+	return h.done
+}
+
+// WaitDone waits until the event loop exits.
+func (h *Harness) WaitDone() error {
+	select {
+	case <-h.done:
+		return nil
+	case <-time.After(h.Timeout):
+		return ErrTimeout
+	}
+}
+
+// Err returns the error returned by MenuScreen.StartContext,
+// it's nil until the event loop exits.
+func (h *Harness) Err() error {
+	select {
+	case <-h.done:
+		return h.err
+	default:
+		return nil
+	}
+}
+
+// Lines returns the rendered text of each row, trailing spaces are trimmed.
+// It returns nil after the MenuScreen finished.
+func (h *Harness) Lines() []string {
+	cells, width, height := h.Screen.GetContents()
+	if len(cells) < width*height {
+		return nil
+	}
+	lines := make([]string, 0, height)
+	for y := 0; y < height; y++ {
+		var sb strings.Builder
+		for x := 0; x < width; x++ {
+			runes := cells[y*width+x].Runes
+			if len(runes) == 0 {
+				sb.WriteRune(' ')
+				continue
+			}
+			sb.WriteString(string(runes))
+			// skip the cell covered by a wide char
+			if runewidth.RuneWidth(runes[0]) == 2 {
+				x++
+			}
+		}
+		lines = append(lines, strings.TrimRight(sb.String(), " "))
+	}
+	return lines
+}
+
+// Text returns the rendered text of the screen, without the trailing blank rows.
+func (h *Harness) Text() string {
+	return strings.TrimRight(strings.Join(h.Lines(), "\n"), "\n")
+}
+
+// Cursor returns the position of the cursor and whether it's visible.
+func (h *Harness) Cursor() (x, y int, visible bool) {
+	return h.Screen.GetCursor()
+}
+
+func parseKeys(keys string) ([]tcell.Event, error) {
+	var events []tcell.Event
+	for len(keys) > 0 {
+		if keys[0] == '<' {
+			if end := strings.IndexByte(keys, '>'); end > 1 {
+				spec := keys[1:end]
+				keys = keys[end+1:]
+				if strings.EqualFold(spec, "lt") {
+					events = append(events, tcell.NewEventKey(tcell.KeyRune, '<', tcell.ModNone))
+					continue
+				}
+				ev, err := menuscreen.NewKeyEvent(spec)
+				if err != nil {
+					return nil, fmt.Errorf("menuscreentest: %w", err)
+				}
+				events = append(events, ev)
+				continue
+			}
+		}
+		// an invalid byte is typed as utf8.RuneError
+		r, size := utf8.DecodeRuneInString(keys)
+		keys = keys[size:]
+		events = append(events, tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone))
+	}
+	return events, nil
+}
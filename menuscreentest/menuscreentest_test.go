@@ -0,0 +1,152 @@
+package menuscreentest
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/sshelll/menuscreen"
+)
+
+func newHarness(t *testing.T, width, height int) *Harness {
+	t.Helper()
+	h, err := New(width, height)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return h
+}
+
+func start(t *testing.T, h *Harness) {
+	t.Helper()
+	if err := h.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+}
+
+func typeKeys(t *testing.T, h *Harness, keys string) {
+	t.Helper()
+	if err := h.Type(keys); err != nil {
+		t.Fatalf("Type(%q): %v", keys, err)
+	}
+}
+
+func finish(t *testing.T, h *Harness, keys string) menuscreen.Result {
+	t.Helper()
+	typeKeys(t, h, keys)
+	if err := h.WaitDone(); err != nil {
+		t.Fatalf("WaitDone: %v", err)
+	}
+	return h.Menu.Result()
+}
+
+func TestSearchAndChoose(t *testing.T) {
+	h := newHarness(t, 40, 10)
+	h.Menu.SetLines("foo", "bar", "baz")
+	start(t, h)
+
+	typeKeys(t, h, "/baz")
+	if text := h.Text(); !strings.Contains(text, "▸ baz") || strings.Contains(text, "foo") {
+		t.Fatalf("unexpected screen:\n%s", text)
+	}
+	r := finish(t, h, "<enter>")
+	if r.Outcome != menuscreen.OutcomeAccepted || r.Index != 2 || r.Query != "baz" || r.Key != "enter" {
+		t.Fatalf("unexpected result: %+v", r)
+	}
+	if err := h.Err(); err != nil {
+		t.Fatalf("Err: %v", err)
+	}
+}
+
+func TestLinesAndCursor(t *testing.T) {
+	h := newHarness(t, 20, 6)
+	h.Menu.SetTitle("T").SetLines("foo", "bar")
+	start(t, h)
+
+	lines := h.Lines()
+	if len(lines) != 6 || lines[0] != "T" || lines[1] != "▸ foo" || lines[2] != "  bar" {
+		t.Fatalf("unexpected lines: %q", lines)
+	}
+	if _, _, visible := h.Cursor(); visible {
+		t.Fatal("the cursor should be hidden in the normal mode")
+	}
+	typeKeys(t, h, "/ba")
+	if x, _, visible := h.Cursor(); !visible || x == 0 {
+		t.Fatalf("the cursor should follow the query, got %d, %v", x, visible)
+	}
+	finish(t, h, "<esc><esc>")
+	if len(h.Lines()) != 0 {
+		t.Fatal("Lines should return nothing after the menu finished")
+	}
+}
+
+func TestTypeLiteralLt(t *testing.T) {
+	h := newHarness(t, 40, 10)
+	h.Menu.SetLines("a<b", "ab")
+	start(t, h)
+	typeKeys(t, h, "/<lt>")
+	if text := h.Text(); !strings.Contains(text, "/<") || !strings.Contains(text, "a<b") || strings.Contains(text, "  ab") {
+		t.Fatalf("unexpected screen:\n%s", text)
+	}
+	finish(t, h, "<esc><esc>")
+}
+
+func TestTypeInvalidKey(t *testing.T) {
+	h := newHarness(t, 40, 10)
+	h.Menu.SetLines("foo")
+	start(t, h)
+	for _, keys := range []string{"<nope>", "/<ctrl-foo>"} {
+		if err := h.Type(keys); err == nil {
+			t.Errorf("Type(%q) should fail", keys)
+		}
+	}
+	finish(t, h, "<esc>")
+}
+
+func TestTypeAfterDone(t *testing.T) {
+	h := newHarness(t, 40, 10)
+	h.Menu.SetLines("foo")
+	start(t, h)
+	finish(t, h, "<esc>")
+	if _, _, ok := h.Menu.ChosenLine(); ok {
+		t.Fatal("esc should not choose a line")
+	}
+	if err := h.Type("j"); !errors.Is(err, ErrFinished) {
+		t.Fatalf("Type returned %v, want ErrFinished", err)
+	}
+}
+
+func TestTypeInvalidUTF8(t *testing.T) {
+	h := newHarness(t, 40, 10)
+	h.Menu.SetLines("foo")
+	start(t, h)
+	typeKeys(t, h, "/\xff")
+	if r := finish(t, h, "<esc><esc>"); r.Outcome != menuscreen.OutcomeAborted {
+		t.Fatalf("unexpected result: %+v", r)
+	}
+}
+
+func TestTypeReturnsMenuError(t *testing.T) {
+	h := newHarness(t, 40, 10)
+	h.Menu.SetLines("foo")
+	if err := h.Menu.Bind("x", func(*menuscreen.MenuScreen) { panic("boom") }); err != nil {
+		t.Fatal(err)
+	}
+	start(t, h)
+	if err := h.Type("x"); err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Fatalf("Type returned %v, want the panic", err)
+	}
+	if h.Menu.Result().Outcome != menuscreen.OutcomeError {
+		t.Fatalf("unexpected result: %+v", h.Menu.Result())
+	}
+}
+
+func TestTypeAfterFinish(t *testing.T) {
+	h := newHarness(t, 40, 10)
+	h.Menu.SetLines("foo")
+	start(t, h)
+	// the keys after enter are left in the event queue
+	if err := h.Type("<enter>" + strings.Repeat("x", 40)); !errors.Is(err, ErrFinished) {
+		t.Fatalf("Type returned %v, want ErrFinished", err)
+	}
+}
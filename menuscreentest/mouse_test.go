@@ -0,0 +1,75 @@
+package menuscreentest
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// mouse feeds the mouse events to the menu, a click is a press and a release.
+func mouse(t *testing.T, h *Harness, x, y int, buttons ...tcell.ButtonMask) {
+	t.Helper()
+	for _, b := range buttons {
+		if err := h.Screen.PostEvent(tcell.NewEventMouse(x, y, b, tcell.ModNone)); err != nil {
+			t.Fatalf("PostEvent: %v", err)
+		}
+	}
+	// a double-click may finish the menu
+	if err := h.Wait(); err != nil && err != ErrFinished {
+		t.Fatalf("Wait: %v", err)
+	}
+}
+
+func TestMouseClick(t *testing.T) {
+	h := newHarness(t, 40, 10)
+	h.Menu.SetTitle("T").SetLines("foo", "bar", "baz").SetMouse(true)
+	start(t, h)
+
+	mouse(t, h, 4, 3, tcell.Button1, tcell.ButtonNone)
+	if lines := h.Lines(); lines[3] != "▸ baz" {
+		t.Fatalf("a click should move the cursor:\n%s", h.Text())
+	}
+	// a click out of the list does nothing
+	mouse(t, h, 4, 8, tcell.Button1, tcell.ButtonNone)
+	finish(t, h, "<enter>")
+	if idx, _, ok := h.Menu.ChosenLine(); !ok || idx != 2 {
+		t.Fatalf("ChosenLine() = %d, %v", idx, ok)
+	}
+}
+
+func TestMouseDoubleClick(t *testing.T) {
+	h := newHarness(t, 40, 10)
+	h.Menu.SetTitle("T").SetLines("foo", "bar").SetMouse(true)
+	start(t, h)
+
+	mouse(t, h, 4, 2, tcell.Button1, tcell.ButtonNone, tcell.Button1, tcell.ButtonNone)
+	if err := h.WaitDone(); err != nil {
+		t.Fatalf("a double-click should accept the line: %v", err)
+	}
+	if idx, _, ok := h.Menu.ChosenLine(); !ok || idx != 1 {
+		t.Fatalf("ChosenLine() = %d, %v", idx, ok)
+	}
+}
+
+func TestMouseWheel(t *testing.T) {
+	var lines []string
+	for i := 0; i < 20; i++ {
+		lines = append(lines, fmt.Sprintf("line%02d", i))
+	}
+	h := newHarness(t, 40, 6)
+	h.Menu.SetTitle("T").SetLines(lines...).SetMouse(true)
+	start(t, h)
+
+	mouse(t, h, 4, 2, tcell.WheelDown, tcell.WheelDown)
+	text := h.Text()
+	if strings.Contains(text, "line01") || !strings.Contains(text, "▸ line02") {
+		t.Fatalf("the wheel should scroll the list and the cursor should follow:\n%s", text)
+	}
+	mouse(t, h, 4, 2, tcell.WheelUp)
+	if text := h.Text(); !strings.Contains(text, "line01") {
+		t.Fatalf("the wheel should scroll the list back:\n%s", text)
+	}
+	finish(t, h, "<esc>")
+}
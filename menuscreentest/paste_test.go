@@ -0,0 +1,73 @@
+package menuscreentest
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// paste feeds the text to the menu as a bracketed paste, like a terminal does.
+func paste(t *testing.T, h *Harness, text string) {
+	t.Helper()
+	events := []tcell.Event{tcell.NewEventPaste(true)}
+	for _, r := range text {
+		switch r {
+		case '\n':
+			events = append(events, tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone))
+		case '\t':
+			events = append(events, tcell.NewEventKey(tcell.KeyTab, 0, tcell.ModNone))
+		default:
+			events = append(events, tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone))
+		}
+	}
+	events = append(events, tcell.NewEventPaste(false))
+	for _, ev := range events {
+		h.Screen.PostEventWait(ev)
+	}
+	if err := h.Wait(); err != nil {
+		t.Fatalf("Wait: %v", err)
+	}
+}
+
+func TestPasteIntoInput(t *testing.T) {
+	h := newHarness(t, 40, 10)
+	h.Menu.SetLines("foo")
+	start(t, h)
+
+	typeKeys(t, h, ":<lt>>")
+	typeKeys(t, h, "<left>")
+	paste(t, h, "foo\tbar\nbaz\n")
+	finish(t, h, "<enter>")
+	if _, ln, ok := h.Menu.ChosenLine(); !ok || ln != "<foo barbaz>" {
+		t.Fatalf("ChosenLine() = %q, %v", ln, ok)
+	}
+}
+
+func TestPasteIntoQuery(t *testing.T) {
+	h := newHarness(t, 40, 10)
+	h.Menu.SetLines("foo", "bar")
+	start(t, h)
+
+	typeKeys(t, h, "/")
+	paste(t, h, "ba\n")
+	if lines := h.Lines(); lines[1] != "  [fuzzy] /ba" || lines[2] != "▸ bar" {
+		t.Fatalf("unexpected screen:\n%s", h.Text())
+	}
+	finish(t, h, "<enter>")
+	if idx, _, ok := h.Menu.ChosenLine(); !ok || idx != 1 {
+		t.Fatalf("ChosenLine() = %d, %v", idx, ok)
+	}
+}
+
+func TestPasteInNormalMode(t *testing.T) {
+	h := newHarness(t, 40, 10)
+	h.Menu.SetLines("foo", "bar")
+	start(t, h)
+
+	// the pasted text is not taken as the commands
+	paste(t, h, "j\n")
+	if lines := h.Lines(); lines[1] != "▸ foo" {
+		t.Fatalf("unexpected screen:\n%s", h.Text())
+	}
+	finish(t, h, "<esc>")
+}
@@ -0,0 +1,105 @@
+package menuscreentest
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sshelll/menuscreen"
+)
+
+// waitText waits until the screen satisfies ok, e.g. after a background load.
+func waitText(t *testing.T, h *Harness, ok func(text string) bool) string {
+	t.Helper()
+	deadline := time.Now().Add(h.Timeout)
+	for {
+		if err := h.Wait(); err != nil {
+			t.Fatalf("Wait: %v", err)
+		}
+		text := h.Text()
+		if ok(text) {
+			return text
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out, the screen is:\n%s", text)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func contains(s string) func(string) bool {
+	return func(text string) bool {
+		return strings.Contains(text, s)
+	}
+}
+
+func TestPreviewOfChosenItem(t *testing.T) {
+	var calls atomic.Int32
+	h := newHarness(t, 60, 10)
+	h.Menu.SetLines("foo", "bar").SetPreview(func(idx int, item *menuscreen.MenuItem) string {
+		calls.Add(1)
+		return fmt.Sprintf("%d: %s", idx, item.Content)
+	})
+	start(t, h)
+
+	waitText(t, h, contains("0: foo"))
+	typeKeys(t, h, "j")
+	waitText(t, h, contains("1: bar"))
+	typeKeys(t, h, "k")
+	waitText(t, h, contains("0: foo"))
+	if n := calls.Load(); n != 2 {
+		t.Fatalf("the preview function was called %d times, want 2 by the cache", n)
+	}
+	finish(t, h, "<esc>")
+}
+
+func TestPreviewScroll(t *testing.T) {
+	var lines []string
+	for i := 0; i < 30; i++ {
+		lines = append(lines, fmt.Sprintf("row%02d", i))
+	}
+	h := newHarness(t, 60, 10)
+	h.Menu.SetLines("foo").SetPreview(func(int, *menuscreen.MenuItem) string {
+		return strings.Join(lines, "\n")
+	})
+	start(t, h)
+
+	waitText(t, h, contains("row00"))
+	typeKeys(t, h, "<shift-down><shift-down>")
+	if text := h.Text(); strings.Contains(text, "row01") || !strings.Contains(text, "row02") {
+		t.Fatalf("shift-down should scroll the preview:\n%s", text)
+	}
+	typeKeys(t, h, "<shift-up>")
+	if text := h.Text(); !strings.Contains(text, "row01") {
+		t.Fatalf("shift-up should scroll the preview back:\n%s", text)
+	}
+	finish(t, h, "<esc>")
+}
+
+func TestPreviewAtBottom(t *testing.T) {
+	h := newHarness(t, 40, 12)
+	h.Menu.SetLines("foo", "bar").
+		SetPreview(func(_ int, item *menuscreen.MenuItem) string { return "about " + item.Content }).
+		SetPreviewWindow(menuscreen.PreviewBottom, 50)
+	start(t, h)
+
+	text := waitText(t, h, contains("about foo"))
+	lines := strings.Split(text, "\n")
+	for i, ln := range lines {
+		if strings.Contains(ln, "about foo") && (i < 3 || strings.Contains(ln, "bar")) {
+			t.Fatalf("the preview should be below the list:\n%s", text)
+		}
+	}
+	finish(t, h, "<esc>")
+}
+
+func TestShiftArrowsWithoutPreview(t *testing.T) {
+	h := newHarness(t, 40, 10)
+	h.Menu.SetLines("a", "b", "c")
+	start(t, h)
+	if r := finish(t, h, "<shift-down><shift-down><shift-up><enter>"); r.Index != 1 {
+		t.Errorf("the cursor is on %d, want 1", r.Index)
+	}
+}
@@ -0,0 +1,99 @@
+package menuscreentest
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/sshelll/menuscreen"
+)
+
+func TestResult(t *testing.T) {
+	tests := []struct {
+		keys    string
+		outcome menuscreen.Outcome
+		index   int
+		content string
+		query   string
+		key     string
+	}{
+		{"j<enter>", menuscreen.OutcomeAccepted, 1, "bar", "", "enter"},
+		{"/ba<enter>", menuscreen.OutcomeAccepted, 1, "bar", "ba", "enter"},
+		{":baz<enter>", menuscreen.OutcomeCustomInput, -1, "baz", "", "enter"},
+		{"<esc>", menuscreen.OutcomeAborted, -1, "", "", "esc"},
+		// enter does nothing if no line was matched
+		{"/zzz<enter><ctrl-q>", menuscreen.OutcomeAborted, -1, "", "zzz", "ctrl-q"},
+		{"j<ctrl-o>", menuscreen.OutcomeAccepted, 1, "bar", "", "ctrl-o"},
+	}
+	for _, tt := range tests {
+		h := newHarness(t, 40, 10)
+		h.Menu.SetLines("foo", "bar")
+		if err := h.Menu.Bind("ctrl-o", "accept"); err != nil {
+			t.Fatal(err)
+		}
+		if err := h.Menu.Bind("ctrl-q", "abort"); err != nil {
+			t.Fatal(err)
+		}
+		start(t, h)
+		r := finish(t, h, tt.keys)
+		content := ""
+		if r.Item != nil {
+			content = r.Item.Content
+		}
+		if r.Outcome != tt.outcome || r.Index != tt.index || content != tt.content || r.Query != tt.query || r.Key != tt.key || r.Err != nil {
+			t.Errorf("%q: unexpected result %+v", tt.keys, r)
+		}
+	}
+}
+
+func TestResultOfItems(t *testing.T) {
+	h := newHarness(t, 40, 10)
+	h.Menu.AppendItems(&menuscreen.MenuItem{Content: "foo", Item: 1}, &menuscreen.MenuItem{Content: "bar", Item: 2})
+	start(t, h)
+	if r := finish(t, h, "j<enter>"); r.Index != 1 || r.Item.Item != 2 {
+		t.Fatalf("unexpected result: %+v", r)
+	}
+}
+
+func TestResultOfError(t *testing.T) {
+	h := newHarness(t, 40, 10)
+	h.Menu.SetLines("foo")
+	if err := h.Menu.Bind("x", func(*menuscreen.MenuScreen) { panic("boom") }); err != nil {
+		t.Fatal(err)
+	}
+	start(t, h)
+	h.Type("x")
+	if err := h.WaitDone(); err != nil {
+		t.Fatal(err)
+	}
+	if r := h.Menu.Result(); r.Outcome != menuscreen.OutcomeError || r.Err == nil || r.Err != h.Err() {
+		t.Fatalf("unexpected result: %+v", r)
+	}
+}
+
+func TestResultOfMarkedEntries(t *testing.T) {
+	tests := []struct {
+		keys    string
+		index   int
+		indexes []int
+	}{
+		{"j<enter>", 1, []int{1}},
+		{"<tab>j<tab><enter>", 0, []int{0, 2}},
+		// the marked entries are accepted though no line was matched
+		{"<tab>/zzz<enter>", -1, []int{0}},
+	}
+	for _, tt := range tests {
+		h := newHarness(t, 40, 10)
+		h.Menu.SetLines("foo", "bar", "baz").SetMultiSelect(true)
+		start(t, h)
+		r := finish(t, h, tt.keys)
+		if r.Outcome != menuscreen.OutcomeAccepted || r.Index != tt.index || !slices.Equal(r.Indexes, tt.indexes) {
+			t.Errorf("%q: unexpected result %+v", tt.keys, r)
+			continue
+		}
+		for i, item := range r.Items {
+			if want := []string{"foo", "bar", "baz"}[r.Indexes[i]]; item.Content != want {
+				t.Errorf("%q: item %d is %q, want %q", tt.keys, i, item.Content, want)
+			}
+		}
+	}
+}
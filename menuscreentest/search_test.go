@@ -0,0 +1,59 @@
+package menuscreentest
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+// shown returns the set of the entries on the screen.
+func shown(h *Harness) map[string]bool {
+	entries := make(map[string]bool)
+	for _, ln := range h.Lines() {
+		if content, ok := strings.CutPrefix(ln, "▸ "); ok {
+			entries[content] = true
+		} else if content, ok := strings.CutPrefix(ln, "  "); ok {
+			entries[content] = true
+		}
+	}
+	return entries
+}
+
+func TestExtendedSearch(t *testing.T) {
+	lines := []string{"main.go", "main_test.go", "go.mod", "README.md"}
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"go !test", []string{"main.go", "go.mod"}},
+		{"^main", []string{"main.go", "main_test.go"}},
+		{".md$", []string{"README.md"}},
+		{"'mod | 'READ", []string{"go.mod", "README.md"}},
+		{"^go.mod$", []string{"go.mod"}},
+	}
+	for _, tt := range tests {
+		h := newHarness(t, 40, 10)
+		h.Menu.SetLines(lines...)
+		start(t, h)
+		typeKeys(t, h, "/"+tt.query)
+		entries := shown(h)
+		for _, ln := range lines {
+			if entries[ln] != slices.Contains(tt.want, ln) {
+				t.Errorf("query %q should match %q:\n%s", tt.query, tt.want, h.Text())
+				break
+			}
+		}
+		finish(t, h, "<esc><esc>")
+	}
+}
+
+func TestPlainSearch(t *testing.T) {
+	h := newHarness(t, 40, 10)
+	h.Menu.SetLines("foo bar", "!foo").SetExtendedSearch(false)
+	start(t, h)
+	typeKeys(t, h, "/!foo")
+	if text := h.Text(); strings.Contains(text, "foo bar") || !strings.Contains(text, "!foo") {
+		t.Fatalf("the syntax should be off:\n%s", text)
+	}
+	finish(t, h, "<esc><esc>")
+}
@@ -0,0 +1,77 @@
+package menuscreentest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sshelll/menuscreen"
+)
+
+func newSectionHarness(t *testing.T) *Harness {
+	t.Helper()
+	h := newHarness(t, 40, 12)
+	h.Menu.AppendItems(
+		menuscreen.NewHeader("Prod"),
+		&menuscreen.MenuItem{Content: "p1"},
+		&menuscreen.MenuItem{Content: "p2"},
+		menuscreen.NewSeparator(""),
+		menuscreen.NewHeader("Dev"),
+		&menuscreen.MenuItem{Content: "d1"},
+	)
+	start(t, h)
+	return h
+}
+
+func TestSectionHeadersAreSkipped(t *testing.T) {
+	h := newSectionHarness(t)
+	// the cursor starts on the first entry and skips the separator and the header
+	r := finish(t, h, "jj<enter>")
+	if r.Outcome != menuscreen.OutcomeAccepted || r.Item.Content != "d1" {
+		t.Fatalf("unexpected result: %+v", r)
+	}
+}
+
+func TestCollapseSection(t *testing.T) {
+	h := newSectionHarness(t)
+	typeKeys(t, h, "<space>")
+	text := strings.Join(h.Lines(), "\n")
+	if !strings.Contains(text, "▸ Prod (2)") || strings.Contains(text, "p1") {
+		t.Fatalf("the section is not collapsed:\n%s", text)
+	}
+	// the cursor stays on the header, which can be expanded again
+	typeKeys(t, h, "<ctrl-space>")
+	text = strings.Join(h.Lines(), "\n")
+	if !strings.Contains(text, "▾ Prod") || !strings.Contains(text, "p2") {
+		t.Fatalf("the section is not expanded:\n%s", text)
+	}
+	finish(t, h, "<esc>")
+}
+
+func TestSearchSections(t *testing.T) {
+	h := newSectionHarness(t)
+	typeKeys(t, h, "/1")
+	text := strings.Join(h.Lines(), "\n")
+	for _, s := range []string{"Prod", "p1", "Dev", "d1"} {
+		if !strings.Contains(text, s) {
+			t.Errorf("%q is not listed:\n%s", s, text)
+		}
+	}
+	if strings.Contains(text, "p2") {
+		t.Errorf("p2 is listed:\n%s", text)
+	}
+	if r := finish(t, h, "<down><enter>"); r.Item.Content != "d1" {
+		t.Fatalf("unexpected result: %+v", r)
+	}
+}
+
+func TestToggleSectionInSearch(t *testing.T) {
+	for _, keys := range []string{"/p<ctrl-space>", "<space>/p"} {
+		h := newSectionHarness(t)
+		typeKeys(t, h, keys)
+		text := strings.Join(h.Lines(), "\n")
+		if !strings.Contains(text, "▾ Prod") || !strings.Contains(text, "p1") || !strings.Contains(text, "p2") {
+			t.Errorf("%q: the matches are not shown under an expanded header:\n%s", keys, text)
+		}
+		finish(t, h, "<esc><esc>")
+	}
+}
@@ -0,0 +1,35 @@
+package menuscreentest
+
+import (
+	"testing"
+
+	"github.com/sshelll/menuscreen"
+)
+
+func TestToggleSort(t *testing.T) {
+	h := newHarness(t, 40, 10)
+	h.Menu.SetLines("xxfoo", "foo", "f-o-o")
+	start(t, h)
+
+	typeKeys(t, h, "/foo")
+	if lines := h.Lines(); lines[2] != "▸ foo" {
+		t.Fatalf("the best match should go first:\n%s", h.Text())
+	}
+	typeKeys(t, h, "<ctrl-s>")
+	if lines := h.Lines(); lines[2] != "▸ xxfoo" || lines[3] != "  foo" || lines[4] != "  f-o-o" {
+		t.Fatalf("ctrl-s should keep the original order:\n%s", h.Text())
+	}
+	finish(t, h, "<esc><esc>")
+}
+
+func TestSortReverse(t *testing.T) {
+	h := newHarness(t, 40, 10)
+	h.Menu.SetLines("foo1", "bar", "foo2").SetSortOrder(menuscreen.SortReverse)
+	start(t, h)
+
+	typeKeys(t, h, "/foo")
+	if lines := h.Lines(); lines[2] != "▸ foo2" || lines[3] != "  foo1" {
+		t.Fatalf("the results should be in the reversed order:\n%s", h.Text())
+	}
+	finish(t, h, "<esc><esc>")
+}
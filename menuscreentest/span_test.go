@@ -0,0 +1,64 @@
+package menuscreentest
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/sshelll/menuscreen"
+)
+
+func newSpanHarness(t *testing.T) *Harness {
+	t.Helper()
+	h := newHarness(t, 40, 10)
+	red := tcell.StyleDefault.Foreground(tcell.ColorRed)
+	h.Menu.AppendItems(
+		&menuscreen.MenuItem{Content: "first", Style: tcell.StyleDefault.Foreground(tcell.ColorBlue)},
+		&menuscreen.MenuItem{Content: "test FAILED", Spans: []menuscreen.Span{{Start: 5, End: 11, Style: red}}},
+	)
+	start(t, h)
+	return h
+}
+
+// fgAt returns the foreground of the rune at the offset from s on the screen.
+func fgAt(t *testing.T, h *Harness, s string, offset int) tcell.Color {
+	t.Helper()
+	for y, ln := range h.Lines() {
+		if i := strings.Index(ln, s); i >= 0 {
+			x := utf8.RuneCountInString(ln[:i]) + offset
+			_, _, style, _ := h.Screen.GetContent(x, y)
+			fg, _, _ := style.Decompose()
+			return fg
+		}
+	}
+	t.Fatalf("%q is not shown:\n%s", s, strings.Join(h.Lines(), "\n"))
+	return 0
+}
+
+func TestSpans(t *testing.T) {
+	h := newSpanHarness(t)
+	if fg := fgAt(t, h, "test FAILED", 5); fg != tcell.ColorRed {
+		t.Errorf("the span is drawn in %v", fg)
+	}
+	if fg := fgAt(t, h, "test FAILED", 0); fg == tcell.ColorRed {
+		t.Error("the rune out of the span is drawn in red")
+	}
+	finish(t, h, "<esc>")
+}
+
+func TestChosenLineOverItemStyle(t *testing.T) {
+	h := newSpanHarness(t)
+	// the first item is blue, the chosen line of the default theme is yellow
+	if fg := fgAt(t, h, "first", 0); fg != tcell.ColorYellow {
+		t.Errorf("the chosen line is drawn in %v", fg)
+	}
+	typeKeys(t, h, "j")
+	if fg := fgAt(t, h, "first", 0); fg != tcell.ColorBlue {
+		t.Errorf("the item is drawn in %v", fg)
+	}
+	if fg := fgAt(t, h, "test FAILED", 5); fg != tcell.ColorYellow {
+		t.Errorf("the span on the chosen line is drawn in %v", fg)
+	}
+	finish(t, h, "<esc>")
+}
@@ -0,0 +1,68 @@
+package menuscreentest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sshelll/menuscreen"
+)
+
+func TestStreamLines(t *testing.T) {
+	ch := make(chan string)
+	h := newHarness(t, 40, 10)
+	h.Menu.SetLines("foo").StreamLines(ch)
+	start(t, h)
+
+	waitText(t, h, contains("loading..."))
+	ch <- "bar"
+	ch <- "baz"
+	waitText(t, h, contains("baz"))
+	close(ch)
+	text := waitText(t, h, func(text string) bool {
+		return !strings.Contains(text, "loading...")
+	})
+	if !strings.Contains(text, "foo") || !strings.Contains(text, "bar") {
+		t.Fatalf("the streamed lines should follow the lines set before:\n%s", text)
+	}
+	finish(t, h, "jj<enter>")
+	if idx, ln, ok := h.Menu.ChosenLine(); !ok || idx != 2 || ln != "baz" {
+		t.Fatalf("ChosenLine() = %d, %q, %v", idx, ln, ok)
+	}
+}
+
+func TestStreamWhileSearching(t *testing.T) {
+	ch := make(chan *menuscreen.MenuItem)
+	h := newHarness(t, 40, 10)
+	h.Menu.StreamItems(ch)
+	start(t, h)
+
+	ch <- &menuscreen.MenuItem{Content: "foo"}
+	waitText(t, h, contains("foo"))
+	typeKeys(t, h, "/ba")
+	ch <- &menuscreen.MenuItem{Content: "bar", Item: 42}
+	ch <- &menuscreen.MenuItem{Content: "qux"}
+	close(ch)
+	text := waitText(t, h, contains("bar"))
+	if strings.Contains(text, "qux") || strings.Contains(text, "foo") {
+		t.Fatalf("the streamed items should be matched by the query:\n%s", text)
+	}
+	finish(t, h, "<enter>")
+	if idx, item, ok := h.Menu.ChosenItem(); !ok || idx != 1 || item.Item != 42 {
+		t.Fatalf("ChosenItem() = %d, %+v, %v", idx, item, ok)
+	}
+}
+
+func TestPostStream(t *testing.T) {
+	h := newHarness(t, 40, 10)
+	h.Menu.SetLines("foo")
+	start(t, h)
+
+	ch := make(chan string, 1)
+	ch <- "bar"
+	close(ch)
+	if err := h.Menu.Post(func(m *menuscreen.MenuScreen) { m.StreamLines(ch) }); err != nil {
+		t.Fatalf("Post: %v", err)
+	}
+	waitText(t, h, contains("bar"))
+	finish(t, h, "<esc>")
+}
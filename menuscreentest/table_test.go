@@ -0,0 +1,71 @@
+package menuscreentest
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/sshelll/menuscreen"
+)
+
+func newTableHarness(t *testing.T) *Harness {
+	t.Helper()
+	h := newHarness(t, 40, 10)
+	h.Menu.AppendItems(
+		&menuscreen.MenuItem{Content: "alice", Columns: []string{"alice", "admin"}},
+		&menuscreen.MenuItem{Content: "bob", Columns: []string{"bob", "alice's friend"}},
+	)
+	h.Menu.SetColumns("NAME", "ROLE")
+	return h
+}
+
+func TestTable(t *testing.T) {
+	h := newTableHarness(t)
+	start(t, h)
+	lines := h.Lines()
+	want := []string{"NAME   ROLE", "alice  admin", "bob    alice's friend"}
+	for i, w := range want {
+		if !strings.HasSuffix(strings.TrimRight(lines[i+1], " "), w) {
+			t.Fatalf("unexpected table:\n%s", strings.Join(lines, "\n"))
+		}
+	}
+	finish(t, h, "<esc>")
+}
+
+func TestSearchColumns(t *testing.T) {
+	h := newTableHarness(t)
+	h.Menu.SetSearchColumns(0)
+	start(t, h)
+	typeKeys(t, h, "/alice")
+	if text := strings.Join(h.Lines(), "\n"); strings.Contains(text, "bob") {
+		t.Fatalf("the second column is searched:\n%s", text)
+	}
+	if r := finish(t, h, "<enter>"); r.Index != 0 {
+		t.Fatalf("unexpected result: %+v", r)
+	}
+}
+
+func TestTableHighlightsEveryColumn(t *testing.T) {
+	h := newTableHarness(t)
+	h.Menu.SetTheme(menuscreen.MonochromeTheme())
+	start(t, h)
+	// 'l' of alice and 'm' of admin
+	typeKeys(t, h, "/lm")
+	y := -1
+	for i, ln := range h.Lines() {
+		if strings.Contains(ln, "alice  admin") {
+			y = i
+		}
+	}
+	if y < 0 {
+		t.Fatalf("alice is not listed:\n%s", strings.Join(h.Lines(), "\n"))
+	}
+	ln := h.Lines()[y]
+	x := utf8.RuneCountInString(ln[:strings.Index(ln, "alice")])
+	for _, dx := range []int{1, 9} {
+		if _, _, style, _ := h.Screen.GetContent(x+dx, y); style != menuscreen.MonochromeTheme().Highlight {
+			t.Errorf("the rune at %d is not highlighted", x+dx)
+		}
+	}
+	finish(t, h, "<esc><esc>")
+}
@@ -0,0 +1,46 @@
+package menuscreentest
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/sshelll/menuscreen"
+)
+
+func TestSetTheme(t *testing.T) {
+	theme := menuscreen.HighContrastTheme()
+	h := newHarness(t, 40, 10)
+	h.Menu.SetTitle("T").SetLines("foo", "bar").SetTheme(theme)
+	start(t, h)
+
+	cells, width, _ := h.Screen.GetContents()
+	for _, c := range []struct {
+		name string
+		x, y int
+		want tcell.Style
+	}{
+		{"title", 0, 0, theme.Title},
+		{"chosen line", 2, 1, theme.ChosenLine},
+		{"content", 2, 2, theme.Content},
+		{"cursor column", 0, 2, theme.CursorCol},
+	} {
+		if got := cells[c.y*width+c.x].Style; got != c.want {
+			t.Errorf("the %s is drawn in %v, want %v", c.name, got, c.want)
+		}
+	}
+	finish(t, h, "<esc>")
+}
+
+func TestDeprecatedStyleAfterNew(t *testing.T) {
+	h := newHarness(t, 40, 10)
+	h.Menu.SetLines("foo", "bar")
+	style := tcell.StyleDefault.Foreground(tcell.ColorRed)
+	menuscreen.SetChosenLineStyle(style)
+	defer menuscreen.SetChosenLineStyle(menuscreen.DarkTheme().ChosenLine)
+	start(t, h)
+
+	if _, _, got, _ := h.Screen.GetContent(2, 1); got != style {
+		t.Errorf("the chosen line is drawn in %v, want %v", got, style)
+	}
+	finish(t, h, "<esc>")
+}
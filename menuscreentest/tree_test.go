@@ -0,0 +1,83 @@
+package menuscreentest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sshelll/menuscreen"
+)
+
+func newTreeHarness(t *testing.T) *Harness {
+	t.Helper()
+	h := newHarness(t, 40, 10)
+	h.Menu.AppendItems(
+		&menuscreen.MenuItem{Content: "src", Children: []*menuscreen.MenuItem{
+			{Content: "main.go"},
+			{Content: "lib", Collapsed: true, Children: []*menuscreen.MenuItem{
+				{Content: "util.go"},
+			}},
+		}},
+		&menuscreen.MenuItem{Content: "README"},
+	)
+	start(t, h)
+	return h
+}
+
+func TestTreeExpandAndCollapse(t *testing.T) {
+	h := newTreeHarness(t)
+	if text := strings.Join(h.Lines(), "\n"); !strings.Contains(text, "▸ lib") || strings.Contains(text, "util.go") {
+		t.Fatalf("lib is not collapsed:\n%s", text)
+	}
+	typeKeys(t, h, "jjl")
+	if text := strings.Join(h.Lines(), "\n"); !strings.Contains(text, "▾ lib") || !strings.Contains(text, "util.go") {
+		t.Fatalf("lib is not expanded:\n%s", text)
+	}
+	// l moves to the first child of an expanded node, h moves back to the parent
+	typeKeys(t, h, "lh")
+	typeKeys(t, h, "h")
+	if text := strings.Join(h.Lines(), "\n"); !strings.Contains(text, "▸ lib") || strings.Contains(text, "util.go") {
+		t.Fatalf("lib is not collapsed again:\n%s", text)
+	}
+	if r := finish(t, h, "<enter>"); r.Index != 2 || r.Item.Content != "lib" {
+		t.Fatalf("unexpected result: %+v", r)
+	}
+}
+
+func TestTreeChooseChild(t *testing.T) {
+	h := newTreeHarness(t)
+	if r := finish(t, h, "jjll<enter>"); r.Index != 3 || r.Item.Content != "util.go" {
+		t.Fatalf("unexpected result: %+v", r)
+	}
+}
+
+func TestTreeSearch(t *testing.T) {
+	h := newTreeHarness(t)
+	typeKeys(t, h, "/util")
+	text := strings.Join(h.Lines(), "\n")
+	for _, s := range []string{"src", "lib", "util.go"} {
+		if !strings.Contains(text, s) {
+			t.Errorf("%q is not listed:\n%s", s, text)
+		}
+	}
+	if strings.Contains(text, "main.go") || strings.Contains(text, "README") {
+		t.Errorf("unmatched lines are listed:\n%s", text)
+	}
+	finish(t, h, "<esc><esc>")
+}
+
+func TestTreeSearchChoosesMatch(t *testing.T) {
+	h := newTreeHarness(t)
+	// the ancestors shown above the match can not be chosen
+	if r := finish(t, h, "/util<enter>"); r.Index != 3 || r.Item.Content != "util.go" {
+		t.Fatalf("unexpected result: %+v", r)
+	}
+}
+
+func TestTreeSearchShowsCollapsedNodeExpanded(t *testing.T) {
+	h := newTreeHarness(t)
+	typeKeys(t, h, "/util")
+	if text := strings.Join(h.Lines(), "\n"); !strings.Contains(text, "▾ lib") {
+		t.Fatalf("lib is drawn collapsed above its child:\n%s", text)
+	}
+	finish(t, h, "<esc><esc>")
+}
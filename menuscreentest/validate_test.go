@@ -0,0 +1,29 @@
+package menuscreentest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sshelll/menuscreen"
+)
+
+func TestInputValidator(t *testing.T) {
+	h := newHarness(t, 40, 10)
+	h.Menu.SetLines("foo").SetInputValidator(menuscreen.ValidateInteger)
+	start(t, h)
+
+	typeKeys(t, h, ":12x")
+	if ln := h.Lines()[2]; !strings.Contains(ln, "input must be an integer") {
+		t.Fatalf("the error should be under the input line:\n%s", h.Text())
+	}
+	// enter is refused while the input is invalid
+	typeKeys(t, h, "<enter>")
+	typeKeys(t, h, "<backspace>")
+	if strings.Contains(h.Text(), "input must be") {
+		t.Fatalf("the error should be cleared once the input is valid:\n%s", h.Text())
+	}
+	finish(t, h, "<enter>")
+	if idx, ln, ok := h.Menu.ChosenLine(); !ok || idx != -1 || ln != "12" {
+		t.Fatalf("ChosenLine() = %d, %q, %v", idx, ln, ok)
+	}
+}
@@ -0,0 +1,97 @@
+package menuscreen
+
+import (
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// doubleClickInterval is the max interval between the clicks of a double-click.
+const doubleClickInterval = 500 * time.Millisecond
+
+type mouseState struct {
+	buttons       tcell.ButtonMask
+	lastClickIdx  int
+	lastClickTime time.Time
+}
+
+// SetMouse enables or disables the mouse, which is disabled by default.
+// Click a line to choose it, double-click to accept it, and use the wheel to scroll.
+// NOTE: the mouse does not work with the inline menu.
+func (menu *MenuScreen) SetMouse(enable bool) *MenuScreen {
+	if enable {
+		menu.screen.EnableMouse()
+	} else {
+		menu.screen.DisableMouse()
+	}
+	return menu
+}
+
+func (menu *MenuScreen) handleMouse(ev *tcell.EventMouse) {
+	x, y := ev.Position()
+	buttons := ev.Buttons()
+	// only the press of a button is a click, the motion and the release are ignored
+	pressed := buttons &^ menu.mouse.buttons
+	menu.mouse.buttons = buttons & (tcell.Button1 | tcell.Button2 | tcell.Button3)
+
+	if menu.inPreview(x, y) {
+		switch {
+		case buttons&tcell.WheelUp != 0:
+			menu.keyPREVIEWUP(nil)
+		case buttons&tcell.WheelDown != 0:
+			menu.keyPREVIEWDOWN(nil)
+		}
+		return
+	}
+
+	switch {
+	case buttons&tcell.WheelUp != 0:
+		menu.scrollBy(-1)
+	case buttons&tcell.WheelDown != 0:
+		menu.scrollBy(1)
+	case pressed&tcell.Button1 != 0:
+		menu.clickAt(x, y, ev.When())
+	}
+}
+
+// clickAt moves the cursor to the clicked line, and accepts it if it was double-clicked.
+func (menu *MenuScreen) clickAt(x, y int, when time.Time) {
+	if menu.mode == modeI || x >= menu.listWidth() {
+		return
+	}
+	n := menu.offset + y - menu.contentTop()
+	if y < menu.contentTop() || n >= menu.offset+menu.pageSize() || n >= menu.entryCount() {
+		return
+	}
+	if !menu.selectable(menu.entries()[n]) {
+		return
+	}
+	menu.lastCursorY, menu.cursorY = menu.cursorY, n
+	if n == menu.mouse.lastClickIdx && when.Sub(menu.mouse.lastClickTime) <= doubleClickInterval {
+		menu.mouse.lastClickIdx = -1
+		menu.keyENTER(nil)
+		return
+	}
+	menu.mouse.lastClickIdx, menu.mouse.lastClickTime = n, when
+}
+
+// scrollBy scrolls the viewport by n lines, the cursor follows if it goes out of the viewport.
+func (menu *MenuScreen) scrollBy(n int) {
+	if menu.mode == modeI {
+		return
+	}
+	size := menu.pageSize()
+	offset := max(0, min(menu.offset+n, menu.entryCount()-size))
+	if offset == menu.offset {
+		return
+	}
+	menu.offset = offset
+	menu.hasFilled = false
+	menu.lastCursorY = menu.cursorY
+	menu.cursorY = max(offset, min(menu.cursorY, offset+size-1))
+}
+
+func (menu *MenuScreen) inPreview(x, y int) bool {
+	px, py, pw, ph, ok := menu.previewRect()
+	return ok && x >= px && x < px+pw && y >= py && y < py+ph
+}
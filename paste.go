@@ -0,0 +1,29 @@
+package menuscreen
+
+import "github.com/gdamore/tcell/v2"
+
+// handlePaste collects the keys between the start and the end of a
+// bracketed paste, and inserts them into the query line or the input line
+// at once, so the lines are matched only once for the whole paste.
+func (menu *MenuScreen) handlePaste(ev *tcell.EventPaste) {
+	if ev.Start() {
+		menu.pasting = true
+		menu.pasted = menu.pasted[:0]
+		return
+	}
+	menu.pasting = false
+	menu.insertRunes(cloneRuneSlice(menu.pasted))
+	menu.pasted = menu.pasted[:0]
+}
+
+// collectPaste collects a key of the pasted text.
+// The newlines are dropped, so that they never accept the menu by accident,
+// and the tabs are taken as spaces.
+func (menu *MenuScreen) collectPaste(ev *tcell.EventKey) {
+	switch ev.Key() {
+	case tcell.KeyRune:
+		menu.pasted = append(menu.pasted, ev.Rune())
+	case tcell.KeyTab:
+		menu.pasted = append(menu.pasted, ' ')
+	}
+}
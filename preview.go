@@ -0,0 +1,226 @@
+package menuscreen
+
+import (
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// PreviewPosition is the position of the preview pane.
+type PreviewPosition int
+
+const (
+	// PreviewRight shows the preview pane to the right of the list.
+	PreviewRight PreviewPosition = iota
+	// PreviewBottom shows the preview pane below the list.
+	PreviewBottom
+)
+
+type preview struct {
+	fn       func(idx int, item *MenuItem) string
+	position PreviewPosition
+	// percent is the size of the pane in percentage of the screen.
+	percent int
+	// cache holds the results by the original index of items.
+	cache   map[int]string
+	pending map[int]struct{}
+	// gen changes when the cache was reset, to drop the stale results.
+	gen int
+	// idx is the original index of the item in the pane.
+	idx    int
+	offset int
+}
+
+// SetPreview shows a preview pane of the chosen item, which is the result of fn.
+// fn runs in a new goroutine so that a slow fn would not block the menu, and
+// the result is cached per item. Use 'shift-up' and 'shift-down' to scroll it.
+func (menu *MenuScreen) SetPreview(fn func(idx int, item *MenuItem) string) *MenuScreen {
+	if fn == nil {
+		menu.preview = nil
+		return menu
+	}
+	menu.preview = &preview{
+		fn:       fn,
+		position: PreviewRight,
+		percent:  50,
+		cache:    make(map[int]string),
+		pending:  make(map[int]struct{}),
+		idx:      -1,
+	}
+	return menu
+}
+
+// SetPreviewWindow sets the position and the size in percentage of the preview pane.
+// Call it after SetPreview.
+func (menu *MenuScreen) SetPreviewWindow(position PreviewPosition, percent int) *MenuScreen {
+	if menu.preview != nil {
+		menu.preview.position = position
+		menu.preview.percent = max(1, min(percent, 99))
+	}
+	return menu
+}
+
+// resetPreviewCache drops the cached previews, it's called when the lines changed.
+func (menu *MenuScreen) resetPreviewCache() {
+	if menu.preview != nil {
+		menu.preview.cache = make(map[int]string)
+		menu.preview.pending = make(map[int]struct{})
+		menu.preview.idx = -1
+		menu.preview.gen++
+	}
+}
+
+// previewRect returns the area of the preview pane, ok is false if there is no pane.
+func (menu *MenuScreen) previewRect() (x, y, width, height int, ok bool) {
+	if menu.preview == nil || menu.mode == modeI {
+		return 0, 0, 0, 0, false
+	}
+	w, h := menu.screen.Size()
+	switch menu.preview.position {
+	case PreviewBottom:
+		height = h * menu.preview.percent / 100
+		// 1 row for the border
+		return 0, h - height, w, height, height > 0 && h-height-1 > menu.contentTop()+1
+	default:
+		width = w * menu.preview.percent / 100
+		// 1 column for the border
+		return w - width, 0, width, h, width > 0 && w-width-1 > 0
+	}
+}
+
+// listWidth returns the width of the area where the list is drawn.
+func (menu *MenuScreen) listWidth() int {
+	w, _ := menu.screen.Size()
+	if x, _, _, _, ok := menu.previewRect(); ok && menu.preview.position == PreviewRight {
+		return x - 1
+	}
+	return w
+}
+
+// previewRows returns the rows taken by the preview pane and its border below the list.
+func (menu *MenuScreen) previewRows() int {
+	if _, _, _, h, ok := menu.previewRect(); ok && menu.preview.position == PreviewBottom {
+		return h + 1
+	}
+	return 0
+}
+
+// drawPreview draws the preview of the chosen item,
+// and loads it in background if it's not cached.
+func (menu *MenuScreen) drawPreview() {
+	x, y, width, height, ok := menu.previewRect()
+	if !ok {
+		return
+	}
+
+	// border
+	if menu.preview.position == PreviewBottom {
+		for i := 0; i < width; i++ {
+			menu.screen.SetContent(x+i, y-1, '─', nil, menu.theme.Content)
+		}
+	} else {
+		for i := 0; i < height; i++ {
+			menu.screen.SetContent(x-1, y+i, '│', nil, menu.theme.Content)
+		}
+	}
+
+	// clear the pane, the list may be drawn without clearing the screen
+	for i := 0; i < height; i++ {
+		for j := 0; j < width; j++ {
+			menu.screen.SetContent(x+j, y+i, ' ', nil, menu.theme.Content)
+		}
+	}
+
+	idx, ok := menu.cursorIdx()
+	if !ok {
+		return
+	}
+	if idx != menu.preview.idx {
+		menu.preview.idx = idx
+		menu.preview.offset = 0
+	}
+
+	text, ok := menu.preview.cache[idx]
+	if !ok {
+		menu.loadPreview(idx)
+		text = loadingText
+	}
+
+	lines := strings.Split(strings.ReplaceAll(text, "\t", "    "), "\n")
+	menu.preview.offset = max(0, min(menu.preview.offset, len(lines)-1))
+	for i, ln := range lines[menu.preview.offset:] {
+		if i >= height {
+			break
+		}
+		cx := x
+		for _, c := range strings.TrimRight(ln, "\r") {
+			r, w, comb := menu.calRuneWidthAndComb(c)
+			if cx+w > x+width {
+				break
+			}
+			menu.screen.SetContent(cx, y+i, r, comb, menu.theme.Content)
+			cx += w
+		}
+	}
+}
+
+// loadPreview runs the preview function in background,
+// the result will be cached and drawn by the event loop.
+func (menu *MenuScreen) loadPreview(idx int) {
+	if _, ok := menu.preview.pending[idx]; ok {
+		return
+	}
+	p := menu.preview
+	p.pending[idx] = struct{}{}
+	item, gen := menu.itemAt(idx), p.gen
+	go func() {
+		defer func() {
+			// the panic of the preview function fails the menu
+			if r := recover(); r != nil {
+				err := panicError(r)
+				menu.post(func(menu *MenuScreen) { menu.fail(err) })
+			}
+		}()
+		text := p.fn(idx, item)
+		menu.post(func(*MenuScreen) {
+			if p.gen != gen {
+				return
+			}
+			delete(p.pending, idx)
+			p.cache[idx] = text
+		})
+	}()
+}
+
+// keyPREVIEWUP scrolls the preview pane up.
+func (menu *MenuScreen) keyPREVIEWUP(*tcell.EventKey) {
+	if menu.preview != nil {
+		menu.preview.offset = max(0, menu.preview.offset-1)
+	}
+}
+
+// keyPREVIEWDOWN scrolls the preview pane down.
+func (menu *MenuScreen) keyPREVIEWDOWN(*tcell.EventKey) {
+	if menu.preview != nil {
+		// the offset will be limited when drawing
+		menu.preview.offset++
+	}
+}
+
+// keySHIFTUP scrolls the preview pane up, or moves the cursor up if there is no preview pane.
+func (menu *MenuScreen) keySHIFTUP(ev *tcell.EventKey) {
+	if menu.preview == nil {
+		menu.keyUP(ev)
+		return
+	}
+	menu.keyPREVIEWUP(ev)
+}
+
+// keySHIFTDOWN scrolls the preview pane down, or moves the cursor down if there is no preview pane.
+func (menu *MenuScreen) keySHIFTDOWN(ev *tcell.EventKey) {
+	if menu.preview == nil {
+		menu.keyDOWN(ev)
+		return
+	}
+	menu.keyPREVIEWDOWN(ev)
+}
@@ -0,0 +1,81 @@
+package menuscreen
+
+// Outcome tells how a MenuScreen ended.
+type Outcome int
+
+const (
+	// OutcomeAborted means the menu was closed without choosing anything.
+	OutcomeAborted Outcome = iota
+	// OutcomeAccepted means an entry of the menu was chosen.
+	OutcomeAccepted
+	// OutcomeCustomInput means the customized content was entered in the input mode.
+	OutcomeCustomInput
+	// OutcomeError means the menu was stopped by an error, e.g. the ctx was done.
+	OutcomeError
+)
+
+func (o Outcome) String() string {
+	switch o {
+	case OutcomeAborted:
+		return "aborted"
+	case OutcomeAccepted:
+		return "accepted"
+	case OutcomeCustomInput:
+		return "custom-input"
+	case OutcomeError:
+		return "error"
+	}
+	return "unknown"
+}
+
+// Result is the result of a finished MenuScreen.
+type Result struct {
+	Outcome Outcome
+	// Index is the original index of the chosen entry,
+	// it's -1 unless the Outcome is OutcomeAccepted.
+	Index int
+	// Item is the chosen entry, a new item is created for the line menu.
+	// For OutcomeCustomInput, its Content is the input.
+	Item *MenuItem
+	// Indexes and Items are the marked entries of the multi-select mode
+	// in order, or the chosen entry if nothing was marked, like ChosenItems.
+	// The Index can be -1 with some entries marked, if the cursor was on none.
+	Indexes []int
+	Items   []*MenuItem
+	// Query is the final query of the search mode.
+	Query string
+	// Key is the key which ended the menu in the format of Bind, e.g. "enter" or "esc".
+	// It's empty if the menu was not ended by a key.
+	Key string
+	// Err is the error returned by StartContext for OutcomeError.
+	Err error
+}
+
+// Result returns the result of the MenuScreen, it works the same for
+// the line menu and the item menu. Call it after Start or StartContext.
+func (menu *MenuScreen) Result() Result {
+	r := Result{Index: -1}
+	if menu.mode == modeS {
+		r.Query = string(menu.query)
+	}
+	if menu.endKey != nil {
+		r.Key = keyName(menu.endKey)
+	}
+
+	switch {
+	case menu.err != nil:
+		r.Outcome, r.Err = OutcomeError, menu.err
+	case !menu.confirmed:
+		r.Outcome = OutcomeAborted
+	case menu.mode == modeI:
+		r.Outcome = OutcomeCustomInput
+		r.Item = &MenuItem{Content: string(menu.input)}
+	default:
+		r.Outcome = OutcomeAccepted
+		if idx, ok := menu.cursorIdx(); ok {
+			r.Index, r.Item = idx, menu.itemAt(idx)
+		}
+		r.Indexes, r.Items, _ = menu.ChosenItems()
+	}
+	return r
+}
@@ -0,0 +1,204 @@
+package menuscreen
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/sshelll/fzflib/algo"
+)
+
+// wholeQueryMatcher is implemented by the matchers which take the whole query
+// as is, the extended search syntax will not be applied to them.
+type wholeQueryMatcher interface {
+	wholeQuery() bool
+}
+
+func (*regexMatcher) wholeQuery() bool {
+	return true
+}
+
+// SetExtendedSearch enables or disables the extended search syntax, which is enabled by default.
+//
+// The query is split by spaces into terms, and the lines should match all of them:
+//
+//	term     match by the current matcher, fuzzy by default
+//	'term    exact match
+//	^term    prefix match
+//	term$    suffix match
+//	^term$   equal match
+//	!term    the lines not containing the term, it can be used with ^ and $
+//	a | b    a or b
+//
+// Use '\ ' to match a space.
+func (menu *MenuScreen) SetExtendedSearch(enable bool) *MenuScreen {
+	menu.plainSearch = !enable
+	return menu
+}
+
+// searchTerm is a term of the extended search syntax.
+type searchTerm struct {
+	text     string
+	matcher  Matcher
+	negative bool
+	// plain means the term is matched by the current matcher as is.
+	plain bool
+}
+
+// match matches the query with the candidates by the current matcher and the extended syntax,
+// all the lines are matched if cands is nil.
+func (menu *MenuScreen) match(query string, cands []int) []MatchResult {
+	m := menu.matcher()
+	if wq, ok := m.(wholeQueryMatcher); menu.plainSearch || (ok && wq.wholeQuery()) {
+		return menu.matchOn(m, query, cands)
+	}
+	groups := parseSearchTerms(query, m)
+	if len(groups) == 1 && len(groups[0]) == 1 && groups[0][0].plain {
+		// the fast path of a plain query
+		return menu.matchOn(m, groups[0][0].text, cands)
+	}
+	if cands == nil {
+		cands = rangeIdxs(0, len(menu.lines))
+	}
+	return menu.matchTerms(groups, cands)
+}
+
+// parseSearchTerms splits the query into the groups of OR terms,
+// the lines should match at least one term of every group.
+func parseSearchTerms(query string, m Matcher) [][]searchTerm {
+	var (
+		groups [][]searchTerm
+		group  []searchTerm
+		or     bool
+	)
+	for _, tok := range splitQuery(query) {
+		if tok == "|" {
+			or = len(group) > 0
+			continue
+		}
+		if !or && len(group) > 0 {
+			groups = append(groups, group)
+			group = nil
+		}
+		or = false
+		group = append(group, parseSearchTerm(tok, m))
+	}
+	if len(group) > 0 {
+		groups = append(groups, group)
+	}
+	return groups
+}
+
+func parseSearchTerm(tok string, m Matcher) searchTerm {
+	// the matcher is left nil until the syntax picks one, so that the
+	// plain terms can be told without comparing the matchers
+	var term searchTerm
+	text := tok
+	if strings.HasPrefix(text, "!") {
+		term.negative = true
+		// the negative term is an exact match by default
+		term.matcher = ExactMatcher()
+		text = text[1:]
+	}
+	switch {
+	case strings.HasPrefix(text, "'"):
+		term.matcher, text = ExactMatcher(), text[1:]
+	case strings.HasPrefix(text, "^") && strings.HasSuffix(text, "$") && len(text) > 2:
+		term.matcher, text = &algoMatcher{name: "equal", fn: algo.EqualMatch}, text[1:len(text)-1]
+	case strings.HasPrefix(text, "^"):
+		term.matcher, text = PrefixMatcher(), text[1:]
+	case strings.HasSuffix(text, "$") && !strings.HasSuffix(text, "\\$"):
+		term.matcher, text = &algoMatcher{name: "suffix", fn: algo.SuffixMatch}, text[:len(text)-1]
+	}
+	if text == "" {
+		// a single '!', '^' and so on is matched as is
+		return searchTerm{text: tok, matcher: m, plain: true}
+	}
+	term.text = text
+	term.plain = term.matcher == nil
+	if term.plain {
+		term.matcher = m
+	}
+	return term
+}
+
+// splitQuery splits the query by spaces, '\ ' is kept as a space.
+func splitQuery(query string) []string {
+	var (
+		toks []string
+		sb   strings.Builder
+	)
+	runes := []rune(query)
+	for i := 0; i < len(runes); i++ {
+		c := runes[i]
+		switch {
+		case c == '\\' && i+1 < len(runes) && runes[i+1] == ' ':
+			sb.WriteRune(' ')
+			i++
+		case c == ' ':
+			if sb.Len() > 0 {
+				toks = append(toks, sb.String())
+				sb.Reset()
+			}
+		default:
+			sb.WriteRune(c)
+		}
+	}
+	if sb.Len() > 0 {
+		toks = append(toks, sb.String())
+	}
+	return toks
+}
+
+// matchTerms narrows down the candidates group by group, the scores are summed
+// and the positions of the positive terms are merged.
+func (menu *MenuScreen) matchTerms(groups [][]searchTerm, cands []int) []MatchResult {
+	scores := make(map[int]int, len(cands))
+	poses := make(map[int]map[int]struct{})
+
+	for _, group := range groups {
+		hits := make(map[int]struct{}, len(cands))
+		for _, term := range group {
+			results := menu.matchOn(term.matcher, term.text, cands)
+			if term.negative {
+				matched := make(map[int]struct{}, len(results))
+				for _, r := range results {
+					matched[r.Index] = struct{}{}
+				}
+				for _, idx := range cands {
+					if _, ok := matched[idx]; !ok {
+						hits[idx] = struct{}{}
+					}
+				}
+				continue
+			}
+			for _, r := range results {
+				hits[r.Index] = struct{}{}
+				scores[r.Index] += r.Score
+				if poses[r.Index] == nil {
+					poses[r.Index] = make(map[int]struct{})
+				}
+				for _, p := range r.Pos {
+					poses[r.Index][p] = struct{}{}
+				}
+			}
+		}
+		next := make([]int, 0, len(hits))
+		for _, idx := range cands {
+			if _, ok := hits[idx]; ok {
+				next = append(next, idx)
+			}
+		}
+		cands = next
+	}
+
+	results := make([]MatchResult, 0, len(cands))
+	for _, idx := range cands {
+		pos := make([]int, 0, len(poses[idx]))
+		for p := range poses[idx] {
+			pos = append(pos, p)
+		}
+		sort.Ints(pos)
+		results = append(results, MatchResult{Index: idx, Score: scores[idx], Pos: pos})
+	}
+	return results
+}
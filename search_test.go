@@ -0,0 +1,96 @@
+package menuscreen
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSplitQuery(t *testing.T) {
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"", nil},
+		{"   ", nil},
+		{"foo", []string{"foo"}},
+		{" foo  bar ", []string{"foo", "bar"}},
+		{`foo\ bar baz`, []string{"foo bar", "baz"}},
+		{`foo\`, []string{`foo\`}},
+		{"a | b", []string{"a", "|", "b"}},
+	}
+	for _, tt := range tests {
+		if got := splitQuery(tt.query); !slices.Equal(got, tt.want) {
+			t.Errorf("splitQuery(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+// termString describes the term by the name of its matcher, e.g. "!exact:foo".
+func termString(term searchTerm) string {
+	s := term.matcher.Name() + ":" + term.text
+	if term.negative {
+		s = "!" + s
+	}
+	return s
+}
+
+func TestParseSearchTerms(t *testing.T) {
+	tests := []struct {
+		query string
+		want  [][]string
+	}{
+		{"foo", [][]string{{"fuzzy:foo"}}},
+		{"'foo", [][]string{{"exact:foo"}}},
+		{"^foo", [][]string{{"prefix:foo"}}},
+		{"foo$", [][]string{{"suffix:foo"}}},
+		{"^foo$", [][]string{{"equal:foo"}}},
+		{"!foo", [][]string{{"!exact:foo"}}},
+		{"!^foo", [][]string{{"!prefix:foo"}}},
+		{"foo bar", [][]string{{"fuzzy:foo"}, {"fuzzy:bar"}}},
+		{"a | 'b c", [][]string{{"fuzzy:a", "exact:b"}, {"fuzzy:c"}}},
+		// the syntax chars alone are matched as is
+		{"! ^ $ '", [][]string{{"fuzzy:!"}, {"fuzzy:^"}, {"fuzzy:$"}, {"fuzzy:'"}}},
+		{`foo\$`, [][]string{{`fuzzy:foo\$`}}},
+		{"| a |", [][]string{{"fuzzy:a"}}},
+	}
+	for _, tt := range tests {
+		var got [][]string
+		for _, group := range parseSearchTerms(tt.query, FuzzyMatcher()) {
+			var terms []string
+			for _, term := range group {
+				terms = append(terms, termString(term))
+			}
+			got = append(got, terms)
+		}
+		if !slices.EqualFunc(got, tt.want, slices.Equal[[]string]) {
+			t.Errorf("parseSearchTerms(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestParseSearchTermsPlain(t *testing.T) {
+	tests := []struct {
+		query string
+		m     Matcher
+		want  []bool
+	}{
+		{"foo", FuzzyMatcher(), []bool{true}},
+		{"foo 'bar", FuzzyMatcher(), []bool{true, false}},
+		{"!foo ^bar", FuzzyMatcher(), []bool{false, false}},
+		{"!", FuzzyMatcher(), []bool{true}},
+		// an exact term is not plain even with the exact matcher
+		{"'foo", ExactMatcher(), []bool{false}},
+		{"foo", ExactMatcher(), []bool{true}},
+	}
+	for _, tt := range tests {
+		var got []bool
+		for _, group := range parseSearchTerms(tt.query, tt.m) {
+			for _, term := range group {
+				got = append(got, term.plain)
+			}
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("plain terms of %q by %s = %v, want %v", tt.query, tt.m.Name(), got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package menuscreen
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// ItemKind is the kind of a MenuItem.
+type ItemKind int
+
+const (
+	// ItemEntry is a normal entry which can be chosen.
+	ItemEntry ItemKind = iota
+	// ItemHeader is the header of a section, the section includes the
+	// entries below it until the next header. It can not be chosen, and the
+	// section can be collapsed by 'space' in the normal mode or 'ctrl-space'.
+	ItemHeader
+	// ItemSeparator is a line between the entries, which can not be chosen.
+	ItemSeparator
+)
+
+// NewHeader creates the header of a section.
+func NewHeader(title string) *MenuItem {
+	return &MenuItem{Content: title, Kind: ItemHeader}
+}
+
+// NewSeparator creates a separator, the label is drawn in the separator if not empty.
+func NewSeparator(label string) *MenuItem {
+	return &MenuItem{Content: label, Kind: ItemSeparator}
+}
+
+// kindOf returns the kind of the n-th line.
+func (menu *MenuScreen) kindOf(n int) ItemKind {
+	if n < len(menu.items) && menu.items[n] != nil {
+		return menu.items[n].Kind
+	}
+	return ItemEntry
+}
+
+// disabled reports whether the n-th line is a disabled item.
+func (menu *MenuScreen) disabled(n int) bool {
+	return n < len(menu.items) && menu.items[n] != nil && menu.items[n].Disabled
+}
+
+// disabledReason returns the reason why the n-th item is disabled.
+func (menu *MenuScreen) disabledReason(n int) string {
+	if reason := menu.items[n].DisabledReason; reason != "" {
+		return reason
+	}
+	return "disabled"
+}
+
+// selectable reports whether the cursor can stop on the entry.
+// The header of a collapsed section is selectable, so that it can be expanded again.
+func (menu *MenuScreen) selectable(ln *matchedLine) bool {
+	switch menu.kindOf(ln.idx) {
+	case ItemEntry:
+		return !ln.context
+	case ItemHeader:
+		return menu.mode != modeS && menu.collapsed[ln.idx]
+	}
+	return false
+}
+
+// syncSections finds the header of each line, it's done only if there are
+// headers or separators.
+func (menu *MenuScreen) syncSections() {
+	if len(menu.heads) == len(menu.lines) {
+		return
+	}
+	hasSection := false
+	for i := range menu.items {
+		if menu.kindOf(i) != ItemEntry {
+			hasSection = true
+			break
+		}
+	}
+	if !hasSection {
+		menu.heads = nil
+		return
+	}
+	menu.heads = make([]int, len(menu.lines))
+	head := -1
+	for i := range menu.lines {
+		if menu.kindOf(i) == ItemHeader {
+			head = i
+		}
+		menu.heads[i] = head
+	}
+}
+
+// headOf returns the header of the section which the n-th line belongs to, or -1.
+func (menu *MenuScreen) headOf(n int) int {
+	if n < len(menu.heads) {
+		return menu.heads[n]
+	}
+	return -1
+}
+
+// hidden reports whether the n-th line is in a collapsed section or under a collapsed node.
+func (menu *MenuScreen) hidden(n int) bool {
+	head := menu.headOf(n)
+	return (head >= 0 && head != n && menu.collapsed[head]) || menu.hiddenInTree(n)
+}
+
+// groupBySection moves the results of the same section together, and puts
+// the header above them. The sections are ordered by their first results.
+func (menu *MenuScreen) groupBySection(results []MatchResult) []MatchResult {
+	if menu.heads == nil {
+		return results
+	}
+	var (
+		order  []int
+		groups = make(map[int][]MatchResult)
+	)
+	for _, r := range results {
+		head := menu.headOf(r.Index)
+		if _, ok := groups[head]; !ok {
+			order = append(order, head)
+		}
+		groups[head] = append(groups[head], r)
+	}
+	grouped := make([]MatchResult, 0, len(results)+len(order))
+	for _, head := range order {
+		if head >= 0 {
+			grouped = append(grouped, MatchResult{Index: head})
+		}
+		grouped = append(grouped, groups[head]...)
+	}
+	return grouped
+}
+
+// keyTOGGLESECTION collapses or expands the section under the cursor.
+// The search results list the matches of every section, so it does nothing
+// in the search mode.
+func (menu *MenuScreen) keyTOGGLESECTION(*tcell.EventKey) {
+	idx, ok := menu.cursorIdx()
+	if !ok || menu.mode != modeN {
+		return
+	}
+	head := menu.headOf(idx)
+	if head < 0 {
+		return
+	}
+	if menu.collapsed[head] {
+		delete(menu.collapsed, head)
+	} else {
+		menu.collapsed[head] = true
+	}
+	menu.resetNormalLines()
+	// keep the cursor on the header, it moves to the first entry
+	// of the section if the section was expanded
+	for i, ln := range menu.entries() {
+		if ln.idx == head {
+			menu.cursorY, menu.lastCursorY = i, i
+			break
+		}
+	}
+	menu.fixCursor()
+	menu.hasFilled = false
+}
+
+// sectionText returns the text drawn for a header or a separator.
+func (menu *MenuScreen) sectionText(ln *matchedLine) string {
+	if menu.kindOf(ln.idx) == ItemSeparator {
+		w := max(0, menu.listWidth()-2)
+		if ln.content == "" {
+			return strings.Repeat("─", w)
+		}
+		label := "── " + ln.content + " "
+		return label + strings.Repeat("─", max(0, w-menu.calRuneWidth(label)))
+	}
+	if menu.collapsed[ln.idx] && menu.mode != modeS {
+		return "▸ " + ln.content + " (" + strconv.Itoa(menu.sectionSize(ln.idx)) + ")"
+	}
+	return "▾ " + ln.content
+}
+
+// countSections returns the count of the headers, separators and the
+// ancestors shown for context in lns.
+func (menu *MenuScreen) countSections(lns matchedLines) int {
+	n := 0
+	for _, ln := range lns {
+		if menu.kindOf(ln.idx) != ItemEntry || ln.context {
+			n++
+		}
+	}
+	return n
+}
+
+// sectionSize returns the count of the entries in the section.
+func (menu *MenuScreen) sectionSize(head int) int {
+	n := 0
+	for i := head + 1; i < len(menu.lines) && menu.headOf(i) == head; i++ {
+		if menu.kindOf(i) == ItemEntry {
+			n++
+		}
+	}
+	return n
+}
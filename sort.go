@@ -0,0 +1,75 @@
+package menuscreen
+
+import (
+	"slices"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// SortOrder is the order of the results in the search mode.
+type SortOrder int
+
+const (
+	// SortByScore puts the best match first, the ties are broken by
+	// the length of the line and then the original index.
+	SortByScore SortOrder = iota
+	// SortByIndex keeps the original order of the lines, the query only filters them.
+	SortByIndex
+	// SortReverse reverses the original order of the lines, the query only filters them.
+	SortReverse
+)
+
+// SetSortOrder sets the order of the results in the search mode, SortByScore by default.
+// Press 'ctrl-s' in the search mode to toggle sorting by score.
+func (menu *MenuScreen) SetSortOrder(order SortOrder) *MenuScreen {
+	menu.sortOrder = order
+	menu.sortToggled = false
+	return menu
+}
+
+// effectiveSortOrder returns the sort order after toggling by 'ctrl-s'.
+func (menu *MenuScreen) effectiveSortOrder() SortOrder {
+	if !menu.sortToggled {
+		return menu.sortOrder
+	}
+	if menu.sortOrder == SortByScore {
+		return SortByIndex
+	}
+	return SortByScore
+}
+
+// sortResults sorts the results by the effective sort order. The orders are
+// total since the indexes are unique, so the result is stable between keystrokes.
+func (menu *MenuScreen) sortResults(results []MatchResult) {
+	switch menu.effectiveSortOrder() {
+	case SortByIndex:
+		slices.SortFunc(results, func(a, b MatchResult) int {
+			return a.Index - b.Index
+		})
+	case SortReverse:
+		slices.SortFunc(results, func(a, b MatchResult) int {
+			return b.Index - a.Index
+		})
+	default:
+		chars := menu.searchIndex().chars
+		slices.SortFunc(results, func(a, b MatchResult) int {
+			if a.Score != b.Score {
+				return b.Score - a.Score
+			}
+			// Length of chars is the count of runes
+			if la, lb := chars[a.Index].Length(), chars[b.Index].Length(); la != lb {
+				return la - lb
+			}
+			return a.Index - b.Index
+		})
+	}
+}
+
+// keyTOGGLESORT toggles sorting by score and re-runs the query.
+func (menu *MenuScreen) keyTOGGLESORT(*tcell.EventKey) {
+	menu.sortToggled = !menu.sortToggled
+	if menu.mode == modeS {
+		menu.cursorY, menu.lastCursorY = 0, 0
+		menu.calMatchedLines()
+	}
+}
@@ -0,0 +1,62 @@
+package menuscreen
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// newTestMenu creates a MenuScreen of the lines on a simulation screen.
+func newTestMenu(t *testing.T, lines ...string) *MenuScreen {
+	t.Helper()
+	menu, err := NewMenuScreenWithScreen(tcell.NewSimulationScreen(""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(menu.Fini)
+	return menu.SetLines(lines...)
+}
+
+func TestSortResults(t *testing.T) {
+	lines := []string{"abcd", "ab", "abc", "ab", "a"}
+	results := []MatchResult{
+		{Index: 0, Score: 10},
+		{Index: 1, Score: 5},
+		{Index: 2, Score: 10},
+		{Index: 3, Score: 5},
+		{Index: 4, Score: 1},
+	}
+	tests := []struct {
+		order   SortOrder
+		toggled bool
+		want    []int
+	}{
+		// the ties of score are broken by the length and then the index
+		{SortByScore, false, []int{2, 0, 1, 3, 4}},
+		{SortByIndex, false, []int{0, 1, 2, 3, 4}},
+		{SortReverse, false, []int{4, 3, 2, 1, 0}},
+		// ctrl-s toggles between by score and by index
+		{SortByScore, true, []int{0, 1, 2, 3, 4}},
+		{SortByIndex, true, []int{2, 0, 1, 3, 4}},
+		{SortReverse, true, []int{2, 0, 1, 3, 4}},
+	}
+	for _, tt := range tests {
+		menu := newTestMenu(t, lines...).SetSortOrder(tt.order)
+		menu.sortToggled = tt.toggled
+
+		// the order of the input should not matter
+		for _, in := range [][]MatchResult{results, slices.Clone(results)} {
+			slices.Reverse(in)
+			sorted := slices.Clone(in)
+			menu.sortResults(sorted)
+			var got []int
+			for _, r := range sorted {
+				got = append(got, r.Index)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("order %d toggled %v: sorted %v, want %v", tt.order, tt.toggled, got, tt.want)
+			}
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package menuscreen
+
+import (
+	"github.com/gdamore/tcell/v2"
+)
+
+// Span styles the runes of MenuItem.Content in [Start, End).
+// The spans of a table row index the Columns joined by spaces.
+type Span struct {
+	Start int
+	End   int
+	Style tcell.Style
+}
+
+// layerStyle draws the style top over base, the colors set by top replace
+// the ones of base, and the attributes of them are combined.
+func layerStyle(base, top tcell.Style) tcell.Style {
+	fg, bg, attrs := top.Decompose()
+	if fg != tcell.ColorDefault {
+		base = base.Foreground(fg)
+	}
+	if bg != tcell.ColorDefault {
+		base = base.Background(bg)
+	}
+	_, _, baseAttrs := base.Decompose()
+	return base.Attributes(baseAttrs | attrs)
+}
+
+// itemStyle returns the style of the n-th line with the base style of the item.
+func (menu *MenuScreen) itemStyle(n int, style tcell.Style) tcell.Style {
+	if n < len(menu.items) && menu.items[n] != nil {
+		return layerStyle(style, menu.items[n].Style)
+	}
+	return style
+}
+
+// itemSpans returns the spans of the n-th line, mapped to the positions in the
+// row of the table, and moved right by shift runes.
+func (menu *MenuScreen) itemSpans(n, shift int) []Span {
+	if n >= len(menu.items) || menu.items[n] == nil || len(menu.items[n].Spans) == 0 {
+		return nil
+	}
+	spans := menu.items[n].Spans
+	if cols := menu.columnsOf(n); cols != nil {
+		spans = menu.rowSpans(cols, spans)
+	}
+	if shift == 0 {
+		return spans
+	}
+	shifted := make([]Span, len(spans))
+	for i, s := range spans {
+		shifted[i] = Span{Start: s.Start + shift, End: s.End + shift, Style: s.Style}
+	}
+	return shifted
+}
+
+// rowSpans maps the spans over the joined columns to the runes of the row.
+func (menu *MenuScreen) rowSpans(cols []string, spans []Span) []Span {
+	var mapped []Span
+	for _, s := range spans {
+		colPos := splitPos(cols, rangeIdxs(0, len(cols)), rangeIdxs(s.Start, s.End))
+		_, pos := menu.formatRow(cols, colPos)
+		for _, p := range pos {
+			mapped = append(mapped, Span{Start: p, End: p + 1, Style: s.Style})
+		}
+	}
+	return mapped
+}
+
+// spanStyle returns the style of the rune at pos with the spans over style,
+// the later spans are drawn over the earlier ones.
+func spanStyle(spans []Span, pos int, style tcell.Style) tcell.Style {
+	for _, s := range spans {
+		if pos >= s.Start && pos < s.End {
+			style = layerStyle(style, s.Style)
+		}
+	}
+	return style
+}
@@ -0,0 +1,56 @@
+package menuscreen
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestLayerStyle(t *testing.T) {
+	base := tcell.StyleDefault.Foreground(tcell.ColorWhite).Background(tcell.ColorBlack).Bold(true)
+	tests := []struct {
+		top  tcell.Style
+		want tcell.Style
+	}{
+		{tcell.StyleDefault, base},
+		{tcell.StyleDefault.Foreground(tcell.ColorRed), base.Foreground(tcell.ColorRed)},
+		{tcell.StyleDefault.Background(tcell.ColorBlue), base.Background(tcell.ColorBlue)},
+		// the attributes are combined
+		{tcell.StyleDefault.Underline(true), base.Underline(true)},
+		{tcell.StyleDefault.Foreground(tcell.ColorRed).Italic(true), base.Foreground(tcell.ColorRed).Italic(true)},
+	}
+	for _, tt := range tests {
+		if got := layerStyle(base, tt.top); got != tt.want {
+			t.Errorf("layerStyle(%v) = %v, want %v", tt.top, got, tt.want)
+		}
+	}
+}
+
+func TestSpanStyle(t *testing.T) {
+	red := tcell.StyleDefault.Foreground(tcell.ColorRed)
+	bold := tcell.StyleDefault.Bold(true)
+	blue := tcell.StyleDefault.Foreground(tcell.ColorBlue)
+	spans := []Span{
+		{Start: 0, End: 3, Style: red},
+		{Start: 2, End: 5, Style: bold},
+		{Start: 4, End: 6, Style: blue},
+	}
+	tests := []struct {
+		pos  int
+		want tcell.Style
+	}{
+		{0, red},
+		{2, red.Bold(true)},
+		{3, bold},
+		// the later spans are drawn over the earlier ones
+		{4, blue.Bold(true)},
+		{5, blue},
+		{6, tcell.StyleDefault},
+		{-1, tcell.StyleDefault},
+	}
+	for _, tt := range tests {
+		if got := spanStyle(spans, tt.pos, tcell.StyleDefault); got != tt.want {
+			t.Errorf("spanStyle(%d) = %v, want %v", tt.pos, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package menuscreen
+
+// streamBatchSize limits the entries appended by one event,
+// so that the menu keeps responding to the keys.
+const streamBatchSize = 1024
+
+// StreamLines appends the lines received from ch while the menu is running,
+// a loading indicator will be shown until ch was closed.
+// Call it before Start, or in the function passed to Post.
+// WARN: do not call StreamItems and StreamLines at the same time.
+func (menu *MenuScreen) StreamLines(ch <-chan string) *MenuScreen {
+	menu.addStream(func() {
+		streamFrom(menu, ch, func(m *MenuScreen, lns []string) {
+			m.lines = append(m.lines, lns...)
+		})
+	})
+	return menu
+}
+
+// StreamItems appends the items received from ch while the menu is running,
+// a loading indicator will be shown until ch was closed.
+// Call it before Start, or in the function passed to Post.
+// WARN: do not call StreamItems and StreamLines at the same time.
+func (menu *MenuScreen) StreamItems(ch <-chan *MenuItem) *MenuScreen {
+	menu.addStream(func() {
+		streamFrom(menu, ch, func(m *MenuScreen, items []*MenuItem) {
+			m.AppendItems(items...)
+		})
+	})
+	return menu
+}
+
+// addStream starts the stream if the menu is running, or defers it to Start.
+func (menu *MenuScreen) addStream(run func()) {
+	menu.streaming++
+	if menu.running {
+		go run()
+		return
+	}
+	menu.streams = append(menu.streams, run)
+}
+
+// startStreams starts all the streams added before Start.
+func (menu *MenuScreen) startStreams() {
+	for _, run := range menu.streams {
+		go run()
+	}
+	menu.streams = nil
+}
+
+// streamFrom receives entries from ch in batches, and appends them on the event loop.
+func streamFrom[T any](menu *MenuScreen, ch <-chan T, appendFn func(*MenuScreen, []T)) {
+	for {
+		var batch []T
+		closed := false
+		select {
+		case <-menu.shutdownCtrl:
+			return
+		case v, ok := <-ch:
+			if !ok {
+				closed = true
+				break
+			}
+			batch = append(batch, v)
+			// take what is ready without blocking
+		drain:
+			for len(batch) < streamBatchSize {
+				select {
+				case v, ok := <-ch:
+					if !ok {
+						closed = true
+						break drain
+					}
+					batch = append(batch, v)
+				default:
+					break drain
+				}
+			}
+		}
+		menu.post(func(m *MenuScreen) {
+			if len(batch) > 0 {
+				appendFn(m, batch)
+				m.onLinesAppended()
+			}
+			if closed {
+				m.streaming--
+			}
+		})
+		if closed {
+			return
+		}
+	}
+}
+
+// onLinesAppended re-runs the query with the new lines,
+// and keeps the cursor on the same line if possible.
+func (menu *MenuScreen) onLinesAppended() {
+	if menu.mode != modeS {
+		return
+	}
+	idx, ok := menu.cursorIdx()
+	menu.calMatchedLines()
+	if !ok {
+		menu.cursorY = 0
+		return
+	}
+	for i, ln := range menu.matchedLns {
+		if ln.idx == idx {
+			menu.cursorY, menu.lastCursorY = i, i
+			return
+		}
+	}
+	menu.cursorY, menu.lastCursorY = 0, 0
+}
@@ -16,58 +16,291 @@
 
 package menuscreen
 
-import "github.com/gdamore/tcell/v2"
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
 
-var (
-	defaultContentStyle = tcell.StyleDefault.
-				Background(tcell.ColorReset).
-				Foreground(tcell.ColorReset)
+	"github.com/gdamore/tcell/v2"
+)
+
+// Theme holds all the styles of a MenuScreen, see MenuScreen.SetTheme.
+type Theme struct {
+	Content    tcell.Style
+	Title      tcell.Style
+	ChosenLine tcell.Style
+	CursorCol  tcell.Style
+	Query      tcell.Style
+	Highlight  tcell.Style
+	Error      tcell.Style
+	Section    tcell.Style
+	Disabled   tcell.Style
+}
 
-	defaultTitleStyle = defaultContentStyle.
-				Bold(true).
-				Italic(true)
+// DarkTheme is the default theme, which fits the dark terminals.
+func DarkTheme() Theme {
+	content := tcell.StyleDefault.
+		Background(tcell.ColorReset).
+		Foreground(tcell.ColorReset)
+	return Theme{
+		Content: content,
+		Title: content.
+			Bold(true).
+			Italic(true),
+		ChosenLine: tcell.StyleDefault.
+			Foreground(tcell.ColorYellow).
+			Background(tcell.ColorReset).
+			Bold(true),
+		CursorCol: content,
+		Query: content.
+			Italic(true),
+		Highlight: content.Bold(true).Reverse(true),
+		Error:     content.Foreground(tcell.ColorRed),
+		Section:   content.Foreground(tcell.ColorAqua).Bold(true),
+		Disabled:  content.Dim(true),
+	}
+}
+
+// LightTheme fits the light terminals, where yellow is hard to read.
+func LightTheme() Theme {
+	theme := DarkTheme()
+	theme.ChosenLine = tcell.StyleDefault.
+		Foreground(tcell.ColorNavy).
+		Background(tcell.ColorReset).
+		Bold(true)
+	theme.CursorCol = theme.Content.
+		Background(tcell.ColorSilver)
+	theme.Section = theme.Content.
+		Foreground(tcell.ColorTeal).
+		Bold(true)
+	return theme
+}
 
-	defaultChosenLineStyle = tcell.StyleDefault.
-				Foreground(tcell.ColorYellow).
-				Background(tcell.ColorReset).
-				Bold(true)
+// HighContrastTheme draws the chosen line and the matched chars in strong colors.
+func HighContrastTheme() Theme {
+	content := tcell.StyleDefault.
+		Background(tcell.ColorBlack).
+		Foreground(tcell.ColorWhite)
+	return Theme{
+		Content: content,
+		Title: content.
+			Foreground(tcell.ColorAqua).
+			Bold(true),
+		ChosenLine: tcell.StyleDefault.
+			Foreground(tcell.ColorBlack).
+			Background(tcell.ColorYellow).
+			Bold(true),
+		CursorCol: content.
+			Background(tcell.ColorGray),
+		Query: content.
+			Foreground(tcell.ColorLime).
+			Bold(true),
+		Highlight: content.
+			Foreground(tcell.ColorFuchsia).
+			Bold(true).
+			Underline(true),
+		Error: content.
+			Foreground(tcell.ColorRed).
+			Bold(true),
+		Section: content.
+			Foreground(tcell.ColorAqua).
+			Bold(true).
+			Underline(true),
+		Disabled: content.
+			Foreground(tcell.ColorGray),
+	}
+}
 
-	defaultChosenLineStyleLight = tcell.StyleDefault.
-					Foreground(tcell.ColorYellow).
-					Background(tcell.ColorReset).
-					Bold(true)
+// MonochromeTheme uses no colors but only the attributes.
+func MonochromeTheme() Theme {
+	content := tcell.StyleDefault
+	return Theme{
+		Content:    content,
+		Title:      content.Bold(true),
+		ChosenLine: content.Reverse(true),
+		CursorCol:  content,
+		Query:      content.Italic(true),
+		Highlight:  content.Underline(true).Bold(true),
+		Error:      content.Bold(true),
+		Section:    content.Bold(true).Underline(true),
+		Disabled:   content.Dim(true),
+	}
+}
 
-	defaultCursorColStyle = defaultContentStyle
+// SetTheme sets the styles of the MenuScreen.
+func (menu *MenuScreen) SetTheme(theme Theme) *MenuScreen {
+	menu.theme = theme
+	menu.themed = true
+	menu.screen.SetStyle(theme.Content)
+	menu.hasFilled = false
+	return menu
+}
 
-	defaultCursorColStyleLight = defaultContentStyle
+// styleSpec is the style in a theme file.
+type styleSpec struct {
+	Fg            string `json:"fg"`
+	Bg            string `json:"bg"`
+	Bold          bool   `json:"bold"`
+	Italic        bool   `json:"italic"`
+	Underline     bool   `json:"underline"`
+	Reverse       bool   `json:"reverse"`
+	Dim           bool   `json:"dim"`
+	Blink         bool   `json:"blink"`
+	StrikeThrough bool   `json:"strikethrough"`
+}
 
-	defaultQueryStyle = defaultContentStyle.
-				Italic(true)
+// themeSpec is the content of a theme file, the missing styles are taken from the base theme.
+type themeSpec struct {
+	Base       string     `json:"base"`
+	Content    *styleSpec `json:"content"`
+	Title      *styleSpec `json:"title"`
+	ChosenLine *styleSpec `json:"chosen_line"`
+	CursorCol  *styleSpec `json:"cursor_col"`
+	Query      *styleSpec `json:"query"`
+	Highlight  *styleSpec `json:"highlight"`
+	Error      *styleSpec `json:"error"`
+	Section    *styleSpec `json:"section"`
+	Disabled   *styleSpec `json:"disabled"`
+}
 
-	defaultHighlightStyle = defaultContentStyle.Bold(true).Reverse(true)
-)
+// LoadTheme loads a theme from a JSON file, see ParseTheme for the format.
+// Only JSON is supported, so that no more dependency is needed.
+func LoadTheme(path string) (Theme, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Theme{}, err
+	}
+	return ParseTheme(data)
+}
+
+// ParseTheme parses a theme in JSON like:
+//
+//	{
+//	  "base": "light",
+//	  "chosen_line": {"fg": "#005fd7", "bold": true},
+//	  "highlight": {"fg": "red", "underline": true}
+//	}
+//
+// The base is one of "dark", "light", "high-contrast" and "monochrome", "dark" by default.
+// The styles are "content", "title", "chosen_line", "cursor_col", "query", "highlight",
+// "error", "section" and "disabled", the missing ones are taken from the base.
+// The colors are the names known by tcell, hex values like "#ff0000",
+// or "reset" for the default color of the terminal.
+func ParseTheme(data []byte) (Theme, error) {
+	var spec themeSpec
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return Theme{}, fmt.Errorf("invalid theme: %w", err)
+	}
+
+	var theme Theme
+	switch strings.ToLower(spec.Base) {
+	case "", "dark":
+		theme = DarkTheme()
+	case "light":
+		theme = LightTheme()
+	case "high-contrast":
+		theme = HighContrastTheme()
+	case "monochrome":
+		theme = MonochromeTheme()
+	default:
+		return Theme{}, fmt.Errorf("invalid theme: unknown base %q", spec.Base)
+	}
+
+	styles := []struct {
+		spec  *styleSpec
+		style *tcell.Style
+	}{
+		{spec.Content, &theme.Content},
+		{spec.Title, &theme.Title},
+		{spec.ChosenLine, &theme.ChosenLine},
+		{spec.CursorCol, &theme.CursorCol},
+		{spec.Query, &theme.Query},
+		{spec.Highlight, &theme.Highlight},
+		{spec.Error, &theme.Error},
+		{spec.Section, &theme.Section},
+		{spec.Disabled, &theme.Disabled},
+	}
+	for _, s := range styles {
+		if s.spec == nil {
+			continue
+		}
+		style, err := s.spec.style()
+		if err != nil {
+			return Theme{}, err
+		}
+		*s.style = style
+	}
+
+	return theme, nil
+}
+
+func (spec *styleSpec) style() (tcell.Style, error) {
+	fg, err := parseColor(spec.Fg)
+	if err != nil {
+		return tcell.StyleDefault, err
+	}
+	bg, err := parseColor(spec.Bg)
+	if err != nil {
+		return tcell.StyleDefault, err
+	}
+	return tcell.StyleDefault.
+		Foreground(fg).
+		Background(bg).
+		Bold(spec.Bold).
+		Italic(spec.Italic).
+		Underline(spec.Underline).
+		Reverse(spec.Reverse).
+		Dim(spec.Dim).
+		Blink(spec.Blink).
+		StrikeThrough(spec.StrikeThrough), nil
+}
+
+func parseColor(name string) (tcell.Color, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	switch name {
+	case "", "reset":
+		return tcell.ColorReset, nil
+	case "default":
+		return tcell.ColorDefault, nil
+	}
+	if c := tcell.GetColor(name); c != tcell.ColorDefault {
+		return c, nil
+	}
+	return tcell.ColorDefault, fmt.Errorf("invalid theme: unknown color %q", name)
+}
+
+// defaultTheme is the theme of the newly created MenuScreen.
+var defaultTheme = DarkTheme()
 
+// Deprecated: use MenuScreen.SetTheme instead, it affects the MenuScreen started later without SetTheme.
 func SetTitleStyle(style tcell.Style) {
-	defaultTitleStyle = style
+	defaultTheme.Title = style
 }
 
+// Deprecated: use MenuScreen.SetTheme instead, it affects the MenuScreen started later without SetTheme.
 func SetContentStyle(style tcell.Style) {
-	defaultContentStyle = style
+	defaultTheme.Content = style
 }
 
+// Deprecated: use MenuScreen.SetTheme instead, it affects the MenuScreen started later without SetTheme.
 func SetChosenLineStyle(style tcell.Style) {
-	defaultChosenLineStyle = style
+	defaultTheme.ChosenLine = style
 }
 
+// Deprecated: use MenuScreen.SetTheme instead, it affects the MenuScreen started later without SetTheme.
 func SetCursorColStyle(style tcell.Style) {
-	defaultCursorColStyle = style
+	defaultTheme.CursorCol = style
 }
 
+// Deprecated: use MenuScreen.SetTheme instead, it affects the MenuScreen started later without SetTheme.
 func SetQueryStyle(style tcell.Style) {
-	defaultQueryStyle = style
+	defaultTheme.Query = style
 }
 
+// Deprecated: use MenuScreen.SetTheme with LightTheme instead, it affects the MenuScreen started later without SetTheme.
 func SetDefaultLightStyle() {
-	defaultChosenLineStyle = defaultChosenLineStyleLight
-	defaultCursorColStyle = defaultCursorColStyleLight
+	light := LightTheme()
+	defaultTheme.ChosenLine = light.ChosenLine
+	defaultTheme.CursorCol = light.CursorCol
 }
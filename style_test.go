@@ -0,0 +1,57 @@
+package menuscreen
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestParseTheme(t *testing.T) {
+	tests := []struct {
+		data string
+		want func() Theme
+	}{
+		{`{}`, DarkTheme},
+		{`{"base": "Light"}`, LightTheme},
+		{`{"base": "monochrome"}`, MonochromeTheme},
+		{`{"base": "high-contrast", "title": {"fg": "#ff0000", "bold": true}}`, func() Theme {
+			theme := HighContrastTheme()
+			theme.Title = tcell.StyleDefault.
+				Foreground(tcell.NewHexColor(0xff0000)).
+				Background(tcell.ColorReset).
+				Bold(true)
+			return theme
+		}},
+		{`{"highlight": {"fg": "red", "bg": "default", "underline": true}}`, func() Theme {
+			theme := DarkTheme()
+			theme.Highlight = tcell.StyleDefault.
+				Foreground(tcell.ColorRed).
+				Background(tcell.ColorDefault).
+				Underline(true)
+			return theme
+		}},
+	}
+	for _, tt := range tests {
+		got, err := ParseTheme([]byte(tt.data))
+		if err != nil {
+			t.Errorf("ParseTheme(%s): %v", tt.data, err)
+			continue
+		}
+		if got != tt.want() {
+			t.Errorf("ParseTheme(%s) = %+v, want %+v", tt.data, got, tt.want())
+		}
+	}
+}
+
+func TestParseThemeInvalid(t *testing.T) {
+	for _, data := range []string{
+		`{`,
+		`{"base": "solarized"}`,
+		`{"title": {"fg": "no-such-color"}}`,
+		`{"query": {"bg": "#zzzzzz"}}`,
+	} {
+		if _, err := ParseTheme([]byte(data)); err == nil {
+			t.Errorf("ParseTheme(%s) should fail", data)
+		}
+	}
+}
@@ -0,0 +1,264 @@
+package menuscreen
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// columnGap is the spaces between two columns.
+const columnGap = 2
+
+// table makes the items with Columns drawn as aligned rows.
+type table struct {
+	headers []string
+	// search and visible are the indexes of the columns to be searched
+	// and drawn, nil means all the columns.
+	search  []int
+	visible []int
+	// widths are the max widths of the columns in the first counted items,
+	// indexed by the columns.
+	widths  []int
+	counted int
+}
+
+// SetColumns makes the menu a table, the Columns of the items are drawn
+// aligned under the headers, and truncated if the terminal is too narrow.
+// The headers can be empty to draw no header row.
+func (menu *MenuScreen) SetColumns(headers ...string) *MenuScreen {
+	menu.table = &table{headers: headers}
+	menu.resetTargets()
+	menu.hasFilled = false
+	return menu
+}
+
+// SetSearchColumns limits the search to the columns, like --nth of fzf,
+// the columns start from 0. All the columns are searched by default.
+func (menu *MenuScreen) SetSearchColumns(cols ...int) *MenuScreen {
+	if menu.table == nil {
+		menu.table = &table{}
+	}
+	menu.table.search = cols
+	menu.resetTargets()
+	return menu
+}
+
+// SetVisibleColumns only draws the columns in order, like --with-nth of fzf,
+// the columns start from 0. All the columns are drawn by default.
+func (menu *MenuScreen) SetVisibleColumns(cols ...int) *MenuScreen {
+	if menu.table == nil {
+		menu.table = &table{}
+	}
+	menu.table.visible = cols
+	menu.hasFilled = false
+	return menu
+}
+
+// resetTargets drops the search targets and the index built on them.
+func (menu *MenuScreen) resetTargets() {
+	menu.targets = nil
+	if menu.table != nil {
+		menu.table.widths, menu.table.counted = nil, 0
+	}
+	menu.resetSearchIndex()
+}
+
+// searchTargets returns the text to be matched of each line, which are the
+// lines themselves, or the search columns joined by spaces for a table.
+func (menu *MenuScreen) searchTargets() []string {
+	if menu.table == nil {
+		return menu.lines
+	}
+	for i := len(menu.targets); i < len(menu.lines); i++ {
+		menu.targets = append(menu.targets, menu.searchTarget(i))
+	}
+	return menu.targets
+}
+
+func (menu *MenuScreen) searchTarget(n int) string {
+	cols := menu.columnsOf(n)
+	if cols == nil {
+		return menu.lines[n]
+	}
+	search := menu.table.search
+	if search == nil {
+		return strings.Join(cols, " ")
+	}
+	fields := make([]string, 0, len(search))
+	for _, c := range search {
+		fields = append(fields, columnAt(cols, c))
+	}
+	return strings.Join(fields, " ")
+}
+
+// columnsOf returns the columns of the n-th line, nil if it's not a row of the table.
+func (menu *MenuScreen) columnsOf(n int) []string {
+	if menu.table == nil || n >= len(menu.items) || menu.items[n] == nil || menu.items[n].Kind != ItemEntry {
+		return nil
+	}
+	return menu.items[n].Columns
+}
+
+func columnAt(cols []string, c int) string {
+	if c >= 0 && c < len(cols) {
+		return cols[c]
+	}
+	return ""
+}
+
+// syncWidths updates the max widths of the columns with the appended items.
+func (menu *MenuScreen) syncWidths() {
+	t := menu.table
+	if t.widths == nil {
+		t.counted = 0
+		for _, h := range t.headers {
+			t.widths = append(t.widths, runewidth.StringWidth(h))
+		}
+	}
+	// the widths only grow when the items are appended
+	for ; t.counted < len(menu.items); t.counted++ {
+		for c, cell := range menu.columnsOf(t.counted) {
+			if c >= len(t.widths) {
+				t.widths = append(t.widths, 0)
+			}
+			t.widths[c] = max(t.widths[c], runewidth.StringWidth(cell))
+		}
+	}
+}
+
+// visibleColumns returns the indexes of the columns to be drawn.
+func (menu *MenuScreen) visibleColumns() []int {
+	menu.syncWidths()
+	if menu.table.visible != nil {
+		return menu.table.visible
+	}
+	return rangeIdxs(0, len(menu.table.widths))
+}
+
+// columnWidths returns the width of each visible column, the widest columns
+// are narrowed first to fit the width of the list.
+func (menu *MenuScreen) columnWidths(visible []int) []int {
+	widths := make([]int, len(visible))
+	total := 0
+	for i, c := range visible {
+		if c >= 0 && c < len(menu.table.widths) {
+			widths[i] = menu.table.widths[c]
+		}
+		total += widths[i]
+	}
+	avail := menu.listWidth() - 2 - columnGap*max(0, len(widths)-1)
+	for total > avail {
+		widest := 0
+		for i, w := range widths {
+			if w > widths[widest] {
+				widest = i
+			}
+		}
+		if widths[widest] <= 1 {
+			break
+		}
+		widths[widest]--
+		total--
+	}
+	return widths
+}
+
+// hasColumnHeaders reports whether the header row of the table is drawn.
+func (menu *MenuScreen) hasColumnHeaders() bool {
+	if menu.table == nil {
+		return false
+	}
+	for _, h := range menu.table.headers {
+		if h != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// columnHeaderText returns the header row of the table.
+func (menu *MenuScreen) columnHeaderText() string {
+	row, _ := menu.formatRow(menu.table.headers, nil)
+	return row
+}
+
+// rowText returns the row of the n-th line in the table, and the highlight
+// positions in the row mapped from the positions in the search target.
+func (menu *MenuScreen) rowText(n int, pos []int) (string, []int) {
+	return menu.formatRow(menu.columnsOf(n), menu.columnPos(n, pos))
+}
+
+// columnPos maps the positions in the search target to the positions in each column.
+func (menu *MenuScreen) columnPos(n int, pos []int) map[int][]int {
+	cols := menu.columnsOf(n)
+	search := menu.table.search
+	if search == nil {
+		search = rangeIdxs(0, len(cols))
+	}
+	// the positions of some matchers are in descending order
+	sorted := slices.Clone(pos)
+	slices.Sort(sorted)
+	return splitPos(cols, search, sorted)
+}
+
+// splitPos maps the positions in the columns of order joined by spaces
+// to the positions in each column, pos should be sorted.
+func splitPos(cols []string, order []int, pos []int) map[int][]int {
+	if len(pos) == 0 {
+		return nil
+	}
+	colPos := make(map[int][]int)
+	start, i := 0, 0
+	for _, c := range order {
+		end := start + len([]rune(columnAt(cols, c)))
+		for ; i < len(pos) && pos[i] < end; i++ {
+			if pos[i] >= start {
+				colPos[c] = append(colPos[c], pos[i]-start)
+			}
+		}
+		// skip the space between the fields
+		start = end + 1
+	}
+	return colPos
+}
+
+// formatRow aligns the columns by the widths, and returns the row with
+// the highlight positions in it.
+func (menu *MenuScreen) formatRow(cols []string, colPos map[int][]int) (string, []int) {
+	visible := menu.visibleColumns()
+	widths := menu.columnWidths(visible)
+	var (
+		sb    strings.Builder
+		pos   []int
+		runes int
+	)
+	for i, c := range visible {
+		if i > 0 {
+			sb.WriteString(strings.Repeat(" ", columnGap))
+			runes += columnGap
+		}
+		cell := columnAt(cols, c)
+		if runewidth.StringWidth(cell) > widths[i] {
+			cell = runewidth.Truncate(cell, widths[i], "…")
+		}
+		shown := len([]rune(cell))
+		if strings.HasSuffix(cell, "…") && cell != columnAt(cols, c) {
+			shown--
+		}
+		for _, p := range colPos[c] {
+			if p < shown {
+				pos = append(pos, runes+p)
+			}
+		}
+		sb.WriteString(cell)
+		runes += len([]rune(cell))
+		// the last column needs no padding
+		if i < len(visible)-1 {
+			pad := widths[i] - runewidth.StringWidth(cell)
+			sb.WriteString(strings.Repeat(" ", max(0, pad)))
+			runes += max(0, pad)
+		}
+	}
+	return sb.String(), pos
+}
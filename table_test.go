@@ -0,0 +1,121 @@
+package menuscreen
+
+import (
+	"reflect"
+	"testing"
+)
+
+// newTestTable creates a table of the rows on a simulation screen.
+func newTestTable(t *testing.T, rows ...[]string) *MenuScreen {
+	t.Helper()
+	menu := newTestMenu(t)
+	for _, cols := range rows {
+		menu.AppendItems(&MenuItem{Content: cols[0], Columns: cols})
+	}
+	return menu.SetColumns()
+}
+
+func TestSearchTargets(t *testing.T) {
+	menu := newTestTable(t, []string{"a", "bb", "ccc"}, []string{"d"})
+	if got, want := menu.searchTargets(), []string{"a bb ccc", "d"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("searchTargets() = %q, want %q", got, want)
+	}
+	menu.SetSearchColumns(2, 0)
+	if got, want := menu.searchTargets(), []string{"ccc a", " d"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("searchTargets() of the columns 2, 0 = %q, want %q", got, want)
+	}
+}
+
+func TestColumnPos(t *testing.T) {
+	tests := []struct {
+		search []int
+		pos    []int
+		want   map[int][]int
+	}{
+		{nil, nil, nil},
+		// "ab cd ef"
+		{nil, []int{0, 3, 4, 7}, map[int][]int{0: {0}, 1: {0, 1}, 2: {1}}},
+		// the space between the fields is not in any column
+		{nil, []int{2}, map[int][]int{}},
+		// "ef ab"
+		{[]int{2, 0}, []int{1, 3}, map[int][]int{2: {1}, 0: {0}}},
+		// the positions of some matchers are in descending order
+		{nil, []int{7, 4, 3, 0}, map[int][]int{0: {0}, 1: {0, 1}, 2: {1}}},
+	}
+	for _, tt := range tests {
+		menu := newTestTable(t, []string{"ab", "cd", "ef"})
+		menu.SetSearchColumns(tt.search...)
+		if tt.search == nil {
+			menu.table.search = nil
+		}
+		if got := menu.columnPos(0, tt.pos); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("columnPos(%v) of the columns %v = %v, want %v", tt.pos, tt.search, got, tt.want)
+		}
+	}
+}
+
+func TestRowText(t *testing.T) {
+	menu := newTestTable(t, []string{"a", "bbb"}, []string{"cc", "d"})
+	tests := []struct {
+		n       int
+		visible []int
+		pos     []int
+		want    string
+		wantPos []int
+	}{
+		{0, nil, nil, "a   bbb", nil},
+		{1, nil, nil, "cc  d", nil},
+		// "a bbb"
+		{0, nil, []int{0, 3}, "a   bbb", []int{0, 5}},
+		{0, []int{1, 0}, []int{0, 3}, "bbb  a", []int{1, 5}},
+		// the hidden columns are not highlighted
+		{0, []int{1}, []int{0, 2}, "bbb", []int{0}},
+	}
+	for _, tt := range tests {
+		menu.SetVisibleColumns(tt.visible...)
+		if tt.visible == nil {
+			menu.table.visible = nil
+		}
+		got, gotPos := menu.rowText(tt.n, tt.pos)
+		if got != tt.want || !reflect.DeepEqual(gotPos, tt.wantPos) {
+			t.Errorf("rowText(%d, %v) of the columns %v = %q, %v, want %q, %v",
+				tt.n, tt.pos, tt.visible, got, gotPos, tt.want, tt.wantPos)
+		}
+	}
+}
+
+func TestRowTextTruncated(t *testing.T) {
+	menu := newTestTable(t, []string{"abcdefghij", "xy"})
+	menu.screen.SetSize(12, 10)
+	// 8 columns are left besides the cursor and the gap, the widest one is narrowed
+	if got, _ := menu.rowText(0, nil); got != "abcde…  xy" {
+		t.Errorf("rowText() = %q", got)
+	}
+	// the truncated chars are not highlighted
+	if _, pos := menu.rowText(0, []int{3, 8}); !reflect.DeepEqual(pos, []int{3}) {
+		t.Errorf("unexpected positions %v", pos)
+	}
+}
+
+func TestSplitPos(t *testing.T) {
+	cols := []string{"ab", "世界", "c"}
+	tests := []struct {
+		order []int
+		pos   []int
+		want  map[int][]int
+	}{
+		{[]int{0, 1, 2}, nil, nil},
+		// "ab 世界 c", the positions are in runes
+		{[]int{0, 1, 2}, []int{1, 3, 4, 6}, map[int][]int{0: {1}, 1: {0, 1}, 2: {0}}},
+		{[]int{0, 1, 2}, []int{2, 5}, map[int][]int{}},
+		// "c ab"
+		{[]int{2, 0}, []int{0, 3}, map[int][]int{2: {0}, 0: {1}}},
+		// the missing columns are empty
+		{[]int{5, 0}, []int{1}, map[int][]int{0: {0}}},
+	}
+	for _, tt := range tests {
+		if got := splitPos(cols, tt.order, tt.pos); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitPos(%v, %v) = %v, want %v", tt.order, tt.pos, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,238 @@
+package menuscreen
+
+import (
+	"slices"
+	"strings"
+)
+
+// tree keeps the structure of the items with Children, the children are
+// flattened into the lines right after their parent, so the lines are in
+// the pre-order of the tree.
+type tree struct {
+	// parents and depths of the lines, the parent of a root is -1.
+	parents []int
+	depths  []int
+}
+
+// appendTree appends the item and its descendants to the lines.
+func (menu *MenuScreen) appendTree(item *MenuItem, parent, depth int) {
+	if item.Content == "" && item.Columns != nil {
+		item.Content = strings.Join(item.Columns, " ")
+	}
+	n := len(menu.lines)
+	menu.items = append(menu.items, item)
+	menu.lines = append(menu.lines, item.Content)
+	if menu.tree != nil || depth > 0 || len(item.Children) > 0 {
+		menu.syncTree()
+		menu.tree.parents = append(menu.tree.parents, parent)
+		menu.tree.depths = append(menu.tree.depths, depth)
+	}
+	if len(item.Children) > 0 && item.Collapsed {
+		menu.collapsed[n] = true
+	}
+	for _, child := range item.Children {
+		menu.appendTree(child, n, depth+1)
+	}
+}
+
+// syncTree creates the tree once an item has children, the lines appended
+// before it are the roots.
+func (menu *MenuScreen) syncTree() {
+	if menu.tree == nil {
+		menu.tree = &tree{}
+	}
+	t := menu.tree
+	for len(t.parents) < len(menu.lines)-1 {
+		t.parents = append(t.parents, -1)
+		t.depths = append(t.depths, 0)
+	}
+}
+
+// parentOf returns the parent of the n-th line, or -1.
+func (menu *MenuScreen) parentOf(n int) int {
+	if menu.tree == nil || n >= len(menu.tree.parents) {
+		return -1
+	}
+	return menu.tree.parents[n]
+}
+
+// depthOf returns the depth of the n-th line in the tree, 0 for the roots.
+func (menu *MenuScreen) depthOf(n int) int {
+	if menu.tree == nil || n >= len(menu.tree.depths) {
+		return 0
+	}
+	return menu.tree.depths[n]
+}
+
+// hasChildren reports whether the n-th line is a node with children.
+func (menu *MenuScreen) hasChildren(n int) bool {
+	return menu.parentOf(n+1) == n && n >= 0
+}
+
+// hiddenInTree reports whether the n-th line is under a collapsed node.
+func (menu *MenuScreen) hiddenInTree(n int) bool {
+	for p := menu.parentOf(n); p >= 0; p = menu.parentOf(p) {
+		if menu.collapsed[p] {
+			return true
+		}
+	}
+	return false
+}
+
+// withAncestors adds the ancestors of the results, so that the matching
+// descendants are shown under their ancestors. The siblings are kept in the
+// order of their best results, and the ancestors which are not matched are
+// returned in context, they are shown but can not be chosen.
+func (menu *MenuScreen) withAncestors(results []MatchResult) ([]MatchResult, map[int]bool) {
+	if menu.tree == nil {
+		return results, nil
+	}
+	// rank of a node is the order of the best result in its subtree
+	rank := make(map[int]int, len(results))
+	matched := make(map[int]MatchResult, len(results))
+	for i, r := range results {
+		matched[r.Index] = r
+		for n := r.Index; n >= 0; n = menu.parentOf(n) {
+			if _, ok := rank[n]; ok {
+				break
+			}
+			rank[n] = i
+		}
+	}
+	children := make(map[int][]int)
+	for n := range rank {
+		p := menu.parentOf(n)
+		children[p] = append(children[p], n)
+	}
+	for _, c := range children {
+		slices.SortFunc(c, func(a, b int) int {
+			return rank[a] - rank[b]
+		})
+	}
+
+	var (
+		sorted  = make([]MatchResult, 0, len(rank))
+		context = make(map[int]bool)
+		walk    func(p int)
+	)
+	walk = func(p int) {
+		for _, n := range children[p] {
+			r, ok := matched[n]
+			if !ok {
+				r = MatchResult{Index: n}
+				context[n] = true
+			}
+			sorted = append(sorted, r)
+			walk(n)
+		}
+	}
+	walk(-1)
+	return sorted, context
+}
+
+// layoutTree draws the indentation guides of the lines in the tree.
+func (menu *MenuScreen) layoutTree(lns matchedLines) {
+	if menu.tree == nil {
+		return
+	}
+	// the last child of each parent in lns
+	last := make([]bool, len(lns))
+	seen := make(map[int]bool)
+	for i := len(lns) - 1; i >= 0; i-- {
+		p := menu.parentOf(lns[i].idx)
+		last[i] = !seen[p]
+		seen[p] = true
+	}
+	// guides drawn before the children of each node
+	guides := make(map[int]string)
+	for i, ln := range lns {
+		ln.open = seen[ln.idx]
+		p := menu.parentOf(ln.idx)
+		if p < 0 {
+			ln.guide = ""
+			guides[ln.idx] = ""
+			continue
+		}
+		if last[i] {
+			ln.guide = guides[p] + "└─"
+			guides[ln.idx] = guides[p] + "  "
+		} else {
+			ln.guide = guides[p] + "├─"
+			guides[ln.idx] = guides[p] + "│ "
+		}
+	}
+}
+
+// treeText returns the text of the entry with the indentation guides.
+// A node is drawn expanded when its children are shown, the matches of a
+// search are shown under their ancestors even if they are collapsed.
+func (menu *MenuScreen) treeText(ln *matchedLine) string {
+	switch {
+	case menu.hasChildren(ln.idx) && ln.open:
+		return ln.guide + "▾ "
+	case menu.hasChildren(ln.idx):
+		return ln.guide + "▸ "
+	case menu.depthOf(ln.idx) > 0:
+		return ln.guide + "─ "
+	}
+	return "  "
+}
+
+// keyEXPAND expands the node under the cursor, or moves the cursor to its
+// first child if it was expanded.
+func (menu *MenuScreen) keyEXPAND() {
+	idx, ok := menu.cursorIdx()
+	if !ok || !menu.hasChildren(idx) {
+		return
+	}
+	if menu.collapsed[idx] {
+		delete(menu.collapsed, idx)
+		menu.resetNormalLines()
+		menu.cursorTo(idx)
+		return
+	}
+	menu.cursorTo(idx + 1)
+}
+
+// keyCOLLAPSE collapses the node under the cursor, or moves the cursor to
+// its parent if it was collapsed or a leaf.
+func (menu *MenuScreen) keyCOLLAPSE() {
+	idx, ok := menu.cursorIdx()
+	if !ok {
+		return
+	}
+	if menu.hasChildren(idx) && !menu.collapsed[idx] {
+		menu.collapsed[idx] = true
+		menu.resetNormalLines()
+		menu.cursorTo(idx)
+		return
+	}
+	if p := menu.parentOf(idx); p >= 0 {
+		menu.cursorTo(p)
+	}
+}
+
+// cursorTo moves the cursor to the entry of the n-th line.
+func (menu *MenuScreen) cursorTo(n int) {
+	menu.lastCursorY = menu.cursorY
+	for i, ln := range menu.entries() {
+		if ln.idx == n {
+			menu.cursorY = i
+			break
+		}
+	}
+	menu.fixCursor()
+	menu.hasFilled = false
+}
+
+// shiftPos moves the highlight positions right by n runes.
+func shiftPos(pos []int, n int) []int {
+	if n == 0 || len(pos) == 0 {
+		return pos
+	}
+	shifted := make([]int, len(pos))
+	for i, p := range pos {
+		shifted[i] = p + n
+	}
+	return shifted
+}
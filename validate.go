@@ -0,0 +1,85 @@
+package menuscreen
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// SetInputValidator sets the validator of the input mode, which runs on every
+// change of the input and on enter. The error is shown under the input line,
+// and the menu can not be confirmed until the validator returns nil.
+// The built-in validators are ValidateNonEmpty, ValidateInteger,
+// ValidateMatch and MenuScreen.ValidateNotExisting, use ValidateAll
+// to combine them.
+func (menu *MenuScreen) SetInputValidator(validator func(input string) error) *MenuScreen {
+	menu.validator = validator
+	menu.inputErr = nil
+	menu.hasFilled = false
+	return menu
+}
+
+// ValidateNonEmpty refuses the input which is empty or only has spaces.
+func ValidateNonEmpty(input string) error {
+	if strings.TrimSpace(input) == "" {
+		return errors.New("input must not be empty")
+	}
+	return nil
+}
+
+// ValidateInteger refuses the input which is not a decimal integer.
+func ValidateInteger(input string) error {
+	if _, err := strconv.Atoi(input); err != nil {
+		return errors.New("input must be an integer")
+	}
+	return nil
+}
+
+// ValidateMatch returns a validator which refuses the input not matching the
+// regular expression, it panics if the expression can not be parsed.
+func ValidateMatch(expr string) func(input string) error {
+	re := regexp.MustCompile(expr)
+	return func(input string) error {
+		if !re.MatchString(input) {
+			return fmt.Errorf("input must match %s", expr)
+		}
+		return nil
+	}
+}
+
+// ValidateAll returns a validator which runs the validators in order,
+// and returns the first error.
+func ValidateAll(validators ...func(input string) error) func(input string) error {
+	return func(input string) error {
+		for _, v := range validators {
+			if err := v(input); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// ValidateNotExisting refuses the input which equals to a line of the menu,
+// use it like menu.SetInputValidator(menu.ValidateNotExisting).
+func (menu *MenuScreen) ValidateNotExisting(input string) error {
+	for _, ln := range menu.lines {
+		if ln == input {
+			return fmt.Errorf("%q already exists", input)
+		}
+	}
+	return nil
+}
+
+// validateInput runs the validator over the input, and keeps the error
+// to be shown under the input line.
+func (menu *MenuScreen) validateInput() bool {
+	if menu.validator == nil {
+		menu.inputErr = nil
+		return true
+	}
+	menu.inputErr = menu.validator(string(menu.input))
+	return menu.inputErr == nil
+}
@@ -0,0 +1,49 @@
+package menuscreen
+
+import "testing"
+
+func TestValidators(t *testing.T) {
+	menu := &MenuScreen{}
+	menu.SetLines("foo", "bar")
+	tests := []struct {
+		name     string
+		validate func(string) error
+		input    string
+		ok       bool
+	}{
+		{"non-empty", ValidateNonEmpty, "x", true},
+		{"non-empty", ValidateNonEmpty, "", false},
+		{"non-empty", ValidateNonEmpty, " \t", false},
+		{"integer", ValidateInteger, "-42", true},
+		{"integer", ValidateInteger, "4.2", false},
+		{"integer", ValidateInteger, "", false},
+		{"match", ValidateMatch(`^v\d+$`), "v12", true},
+		{"match", ValidateMatch(`^v\d+$`), "v1.2", false},
+		{"not existing", menu.ValidateNotExisting, "baz", true},
+		{"not existing", menu.ValidateNotExisting, "foo", false},
+		{"all", ValidateAll(ValidateNonEmpty, ValidateInteger), "7", true},
+		{"all", ValidateAll(ValidateNonEmpty, ValidateInteger), "x", false},
+		{"all", ValidateAll(), "", true},
+	}
+	for _, tt := range tests {
+		if err := tt.validate(tt.input); (err == nil) != tt.ok {
+			t.Errorf("%s(%q) = %v", tt.name, tt.input, err)
+		}
+	}
+}
+
+func TestValidateAllReturnsFirstError(t *testing.T) {
+	err := ValidateAll(ValidateNonEmpty, ValidateInteger)("")
+	if err == nil || err.Error() != ValidateNonEmpty("").Error() {
+		t.Fatalf("ValidateAll returned %v", err)
+	}
+}
+
+func TestValidateMatchPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("ValidateMatch should panic on an invalid expression")
+		}
+	}()
+	ValidateMatch("(")
+}